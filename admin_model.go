@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/pkg/errors"
+)
+
+// UpsertGame создаёт игру или обновляет её поля по slug.
+// Возвращает false, если в базе уже лежит такая же игра
+func (gs *AccessObject) UpsertGame(ctx context.Context, g *GameModel) (bool, error) {
+	ctx = withQueryMethod(ctx, "UpsertGame")
+
+	err := gs.db.QueryRowContext(ctx, `INSERT INTO games (slug, title, description, rules,
+					code_example, bot_code, logo_uuid, background_uuid)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+					ON CONFLICT (slug) DO UPDATE SET
+						title = EXCLUDED.title,
+						description = EXCLUDED.description,
+						rules = EXCLUDED.rules,
+						code_example = EXCLUDED.code_example,
+						bot_code = EXCLUDED.bot_code,
+						logo_uuid = EXCLUDED.logo_uuid,
+						background_uuid = EXCLUDED.background_uuid
+					WHERE (games.title, games.description, games.rules, games.code_example,
+						games.bot_code, games.logo_uuid, games.background_uuid)
+						IS DISTINCT FROM
+						(EXCLUDED.title, EXCLUDED.description, EXCLUDED.rules, EXCLUDED.code_example,
+						EXCLUDED.bot_code, EXCLUDED.logo_uuid, EXCLUDED.background_uuid)
+					RETURNING id;`, g.Slug, g.Title, g.Description, g.Rules,
+		g.CodeExample, g.BotCode, g.LogoUUID, g.BackgroundUUID).Scan(&g.ID)
+	if err == sql.ErrNoRows {
+		// ничего не поменялось, ON CONFLICT ... WHERE не вернул строку
+		return false, nil
+	}
+	if err != nil {
+		return false, internalError(ctx, "can not upsert game %s: %v", g.Slug, err)
+	}
+
+	return true, nil
+}
+
+// RecomputeWindow пересчитывает сохранённый leaderboard закрытого окна w
+// для всех игр заново по score_events
+func (gs *AccessObject) RecomputeWindow(ctx context.Context, w *LeaderboardWindow) error {
+	ctx = withQueryMethod(ctx, "RecomputeWindow")
+
+	if !w.Closed(time.Now()) {
+		return errors.Wrap(utils.ErrInvalid, "can not recompute open window")
+	}
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return internalError(ctx, "can not open RecomputeWindow transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	// строки leaderboard_snapshots удалятся каскадом
+	_, err = tx.ExecContext(ctx, `DELETE FROM leaderboard_snapshot_windows
+					WHERE period = $1 AND window_start = $2;`, w.Period, w.Start)
+	if err != nil {
+		return internalError(ctx, "can not delete window snapshot: %v", err)
+	}
+
+	if err = gs.snapshotGamesImpl(ctx, tx, w); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return internalError(ctx, "can not commit RecomputeWindow transaction: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/pkg/errors"
+)
+
+// sessionCookie кука сессии warscript-users
+const sessionCookie = "JSESSIONID"
+
+// роли пользователей: admin может всё, что может player
+const (
+	rolePlayer = "player"
+	roleAdmin  = "admin"
+)
+
+var roleLevels = map[string]int{
+	rolePlayer: 1,
+	roleAdmin:  2,
+}
+
+var (
+	// errUnauthorized нет сессии или warscript-users её не признал
+	errUnauthorized = errors.New("unauthorized")
+	// errForbidden сессия есть, а прав нет
+	errForbidden = errors.New("forbidden")
+	// errUsersUnavailable не смогли проверить сессию
+	errUsersUnavailable = errors.New("users_unavailable")
+)
+
+// authUser пользователь, от имени которого пришёл запрос
+type authUser struct {
+	ID   int64
+	Role string
+	// grants роли из базы, по ним проверяет authorize
+	grants []*GrantModel
+}
+
+// can роль пользователя не ниже role
+func (u *authUser) can(role string) bool {
+	return u != nil && roleLevels[u.Role] >= roleLevels[role]
+}
+
+type authUserKey struct{}
+
+// userFromContext пользователь запроса; nil, если роут без авторизации
+func userFromContext(ctx context.Context) *authUser {
+	user, _ := ctx.Value(authUserKey{}).(*authUser)
+	return user
+}
+
+func withUser(ctx context.Context, user *authUser) context.Context {
+	return context.WithValue(ctx, authUserKey{}, user)
+}
+
+// sessionToken токен из куки JSESSIONID, а без неё -- из Authorization: Bearer
+func sessionToken(r *http.Request) string {
+	if cookie, err := r.Cookie(sessionCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	}
+
+	return ""
+}
+
+// roleOf роль пользователя: admin из AdminIDs или с ролью admin на все игры
+// в grants, остальные -- player
+func (s *Server) roleOf(userID int64, grants []*GrantModel) string {
+	for _, id := range s.cfg.AdminIDs {
+		if id == userID {
+			return roleAdmin
+		}
+	}
+	for _, g := range grants {
+		if g.Role == roleAdmin && g.GameSlug == "" {
+			return roleAdmin
+		}
+	}
+
+	return rolePlayer
+}
+
+// authenticate проверяет сессию в warscript-users и поднимает роли
+// пользователя из базы. Если warscript-users не ответил, ошибка --
+// errUsersUnavailable, любая другая его ошибка -- errUnauthorized
+func (s *Server) authenticate(ctx context.Context, token string) (*authUser, error) {
+	userID, err := s.sessionUserID(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	grants, err := s.games.GetUserGrants(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get user grants")
+	}
+
+	return &authUser{
+		ID:     userID,
+		Role:   s.roleOf(userID, grants),
+		grants: grants,
+	}, nil
+}
+
+// sessionUserID ID пользователя сессии token из warscript-users
+func (s *Server) sessionUserID(ctx context.Context, token string) (int64, error) {
+	ctx, cancel := withTimeout(outgoingRequestID(ctx), s.cfg.UsersTimeout)
+	defer cancel()
+
+	ctx, sp := startSpan(ctx, "warscript-users/GetSessionInfo", spanClient)
+	sp.SetAttr("rpc.system", "grpc")
+	start := time.Now()
+	session, err := s.auth.GetSessionInfo(outgoingTraceparent(ctx), &models.SessionToken{Token: token})
+	observeUsersCall(start, err)
+	sp.SetError(err)
+	sp.End()
+	if err != nil {
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded:
+			return 0, errors.Wrapf(errUsersUnavailable, "get session info: %v", err)
+		}
+		return 0, errors.Wrapf(errUnauthorized, "get session info: %v", err)
+	}
+	if session == nil || session.ID <= 0 {
+		return 0, errors.Wrap(errUnauthorized, "empty session")
+	}
+
+	return session.ID, nil
+}
+
+// writeAuthError ответ на ошибку authenticate или authorize
+func writeAuthError(errWriter *errorWriter, err error) {
+	switch errors.Cause(err) {
+	case errUnauthorized:
+		errWriter.WriteCause(http.StatusUnauthorized, err)
+	case errForbidden:
+		errWriter.WriteCause(http.StatusForbidden, err)
+	case errUsersUnavailable:
+		errWriter.WriteCause(http.StatusServiceUnavailable, err)
+	default:
+		writeInternalError(errWriter, err)
+	}
+}
+
+// withAuth пускает к хэндлеру роута только пользователей с ролью route.role,
+// а если задан route.owner -- ещё и только владельца ID из этого параметра
+// пути (admin -- любого). Пользователь кладётся в контекст запроса.
+// Роуты без role отдаются как есть
+func (s *Server) withAuth(route *apiRoute) http.HandlerFunc {
+	if route.role == "" {
+		return route.handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := utils.GetLogger(r, s.logger, "withAuth")
+		errWriter := newErrorWriter(w, logger)
+
+		token := sessionToken(r)
+		if token == "" {
+			errWriter.WriteCause(http.StatusUnauthorized, errors.Wrap(errUnauthorized, "no session token"))
+			return
+		}
+
+		user, err := s.authenticate(r.Context(), token)
+		if err != nil {
+			writeAuthError(errWriter, err)
+			return
+		}
+
+		if !user.can(route.role) {
+			errWriter.WriteCause(http.StatusForbidden,
+				errors.Wrapf(errForbidden, "user %d is %s, %s required", user.ID, user.Role, route.role))
+			return
+		}
+		// невалидный ID не наш вопрос: хэндлер ответит на него 400
+		if ownerID, err := parseID(mux.Vars(r)[route.owner]); route.owner != "" && err == nil &&
+			ownerID != user.ID && !user.can(roleAdmin) {
+			errWriter.WriteCause(http.StatusForbidden,
+				errors.Wrapf(errForbidden, "user %d can not act as %s %d", user.ID, route.owner, ownerID))
+			return
+		}
+
+		route.handler(w, r.WithContext(withUser(r.Context(), user)))
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWithAuth(t *testing.T) {
+	srv := initTests()
+	ts := httptest.NewServer(srv.httpHandler(newHealthChecker(&pingerTest{}, nil)))
+	defer ts.Close()
+
+	cases := []struct {
+		method       string
+		path         string
+		cookie       string
+		bearer       string
+		failure      error
+		expectedCode int
+		expectedBody string
+	}{
+		{method: "PUT", path: "/v1/users/2/following/3", expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"message":"unauthorized"}`},
+		{method: "PUT", path: "/v1/users/2/following/3", cookie: "stolen", expectedCode: http.StatusUnauthorized,
+			expectedBody: `{"message":"unauthorized"}`},
+		{method: "PUT", path: "/v1/users/2/following/3", cookie: testPlayerToken, expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v2/users/2/following/4", bearer: testPlayerToken, expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v1/users/3/following/2", cookie: testPlayerToken, expectedCode: http.StatusForbidden,
+			expectedBody: `{"message":"forbidden"}`},
+		{method: "DELETE", path: "/v1/users/2/following/3", cookie: testAdminToken, expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v1/users/abc/following/3", cookie: testPlayerToken, expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid_params","fields":[{"field":"user_id","error":"invalid"}]}`},
+		{method: "GET", path: "/v1/admin/games/pong/users/1/score-events", cookie: testPlayerToken,
+			expectedCode: http.StatusForbidden, expectedBody: `{"message":"forbidden"}`},
+		{method: "GET", path: "/v1/admin/games/pong/users/1/score-events", cookie: testAdminToken,
+			expectedCode: http.StatusOK},
+		{method: "GET", path: "/v1/admin/games/pong/users/1/score-events", cookie: testAdminToken,
+			failure:      status.Error(codes.Unavailable, "connection refused"),
+			expectedCode: http.StatusServiceUnavailable, expectedBody: `{"message":"users_unavailable"}`},
+		{method: "GET", path: "/v1/admin/games/pong/users/1/score-events", cookie: testAdminToken,
+			failure:      status.Error(codes.NotFound, "session expired"),
+			expectedCode: http.StatusUnauthorized, expectedBody: `{"message":"unauthorized"}`},
+		// открытые роуты сессию не проверяют
+		{method: "GET", path: "/v1/games", cookie: "stolen", failure: status.Error(codes.Unavailable, ""),
+			expectedCode: http.StatusOK},
+	}
+
+	for i, c := range cases {
+		auth := srv.auth.(*sessionAuthClient)
+		auth.SetNextFail(c.failure)
+
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, nil)
+		if c.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookie, Value: c.cookie})
+		}
+		if c.bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+c.bearer)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("[%d] TestWithAuth got unexpected error: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		// неиспользованная ошибка не должна перейти в следующий кейс
+		auth.SetNextFail(nil)
+
+		if resp.StatusCode != c.expectedCode {
+			t.Errorf("[%d] TestWithAuth got code %d %s, expected %d", i, resp.StatusCode, body, c.expectedCode)
+		}
+		if c.expectedBody != "" && string(body) != c.expectedBody {
+			t.Errorf("[%d] TestWithAuth got body %s, expected %s", i, body, c.expectedBody)
+		}
+	}
+}
+
+func TestUserFromContext(t *testing.T) {
+	if user := userFromContext(context.Background()); user != nil {
+		t.Errorf("TestUserFromContext got %+v without user, expected nil", user)
+	}
+
+	srv := initTests()
+	var got *authUser
+	handler := srv.withAuth(&apiRoute{
+		role: rolePlayer,
+		handler: func(w http.ResponseWriter, r *http.Request) {
+			got = userFromContext(r.Context())
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/v1/games", nil)
+	req.AddCookie(&http.Cookie{Name: sessionCookie, Value: testAdminToken})
+	handler(httptest.NewRecorder(), req)
+
+	if got == nil || got.ID != 1 || got.Role != roleAdmin {
+		t.Errorf("TestUserFromContext got %+v, expected admin 1", got)
+	}
+	if !got.can(rolePlayer) || (&authUser{ID: 2, Role: rolePlayer}).can(roleAdmin) {
+		t.Error("TestUserFromContext admin must have player rights and not vice versa")
+	}
+}
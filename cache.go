@@ -0,0 +1,57 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// statsCacheTTL сколько живёт посчитанное распределение очков
+const statsCacheTTL = time.Minute
+
+type cachedItem struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// ttlCache простой потокобезопасный кэш с временем жизни записей
+type ttlCache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]*cachedItem
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:   ttl,
+		items: make(map[string]*cachedItem),
+	}
+}
+
+// Get возвращает значение, если оно есть и ещё не протухло
+func (c *ttlCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(item.expiresAt) {
+		delete(c.items, key)
+		return nil, false
+	}
+
+	return item.value, true
+}
+
+// Set кладёт значение в кэш на ttl
+func (c *ttlCache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = &cachedItem{
+		value:     value,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// command подкоманда бинарника warscript-games
+type command struct {
+	name  string
+	usage string
+	run   func(logger *logrus.Logger, args []string) error
+}
+
+// commands все подкоманды; без подкоманды запускается serve
+var commands = []*command{
+	{name: "serve", usage: "запустить http и grpc сервера", run: runServe},
+	{name: "migrate", usage: "применить миграции схемы (-to, -status)", run: runMigrate},
+	{name: "seed", usage: "применить определения игр из games/<slug>/ (-validate, -diff)", run: runSeed},
+	{name: "export-leaderboard", usage: "выгрузить leaderboard игры в csv или ndjson", run: runExportLeaderboard},
+	{name: "recompute-ratings", usage: "пересчитать leaderboard закрытых окон (-since, -period)", run: runRecomputeRatings},
+	{name: "check-config", usage: "проверить настройки и доступность consul, postgres и warscript-users", run: runCheckConfig},
+}
+
+func findCommand(name string) (*command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+
+	return nil, false
+}
+
+func commandsUsage() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("usage: warscript-games <command> [flags]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(buf, "  %-20s %s\n", cmd.name, cmd.usage)
+	}
+
+	return buf.String()
+}
+
+// runMigrate приводит схему к последней версии или к версии -to:
+// warscript-games migrate [-to 3] [-status]
+func runMigrate(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	target := fs.Int("to", latestSchemaVersion(), "версия схемы; меньше текущей -- откат")
+	status := fs.Bool("status", false, "только показать текущую версию")
+	cfg, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	db, err := connectPostgres(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if *status {
+		return withMigrationLock(db, func(conn *sql.Conn) error {
+			current, err := currentSchemaVersion(conn)
+			if err != nil {
+				return err
+			}
+			logger.Infof("schema version %d, latest %d", current, latestSchemaVersion())
+			return nil
+		})
+	}
+
+	current, err := migrateTo(db, *target, logger)
+	if err != nil {
+		return err
+	}
+	logger.Infof("schema migrated from version %d to %d", current, *target)
+
+	return nil
+}
+
+// runSeed загружает игры из папок games/<slug>/ и применяет изменения:
+// warscript-games seed -dir games
+// С -validate только проверяет файлы, с -diff печатает план без записи
+func runSeed(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	dir := fs.String("dir", "games", "директория с папками игр")
+	validateOnly := fs.Bool("validate", false, "только проверить файлы, без базы")
+	diffOnly := fs.Bool("diff", false, "показать изменения, не применяя их")
+	cfg, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *validateOnly {
+		games, err := loadGameDefinitions(*dir)
+		if err != nil {
+			return err
+		}
+		logger.Infof("%d game definitions are valid", len(games))
+		return nil
+	}
+
+	db, err := connectPostgres(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	// имена игроков для seed не нужны
+	games := NewAccessObject(db, offlineAuthClient{}, cfg.UsersTimeout)
+	diffs, err := planGameDefinitions(context.Background(), games, *dir)
+	if err != nil {
+		return err
+	}
+	printGameDiffs(diffs)
+
+	if *diffOnly {
+		return nil
+	}
+
+	return applyGameDiffs(context.Background(), games, diffs)
+}
+
+// runExportLeaderboard выгружает leaderboard игры в файл:
+// warscript-games export-leaderboard -game pong -format csv -out pong.csv
+func runExportLeaderboard(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("export-leaderboard", flag.ContinueOnError)
+	slug := fs.String("game", "", "slug игры")
+	format := fs.String("format", ExportCSV, "формат выгрузки: csv или ndjson")
+	outPath := fs.String("out", "", "файл для выгрузки (по умолчанию stdout)")
+	cfg, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if *slug == "" {
+		return errors.New("-game is required")
+	}
+	if !IsExportFormat(*format) {
+		return errors.Errorf("unknown format %q", *format)
+	}
+
+	consul, err := connectConsul(cfg)
+	if err != nil {
+		return err
+	}
+
+	db, err := connectPostgres(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	disc, err := newDiscovery(cfg, consul, logger)
+	if err != nil {
+		return err
+	}
+
+	auth, authGPRCConn, err := connectAuth(disc, logger)
+	if err != nil {
+		return err
+	}
+	if authGPRCConn != nil {
+		defer authGPRCConn.Close()
+	}
+
+	games := NewAccessObject(db, auth, cfg.UsersTimeout)
+	if *outPath == "" {
+		return exportLeaderboard(context.Background(), games, *slug, *format, os.Stdout, nil)
+	}
+
+	out, err := os.Create(*outPath)
+	if err != nil {
+		return errors.Wrap(err, "can not create output file")
+	}
+
+	if err = exportLeaderboard(context.Background(), games, *slug, *format, out, nil); err != nil {
+		//nolint: errcheck
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// closedWindows все закрытые к моменту now окна периода period,
+// начиная с окна, в которое попадает since; границы в часовом поясе since
+func closedWindows(period string, since, now time.Time) []*LeaderboardWindow {
+	windows := make([]*LeaderboardWindow, 0)
+	w, ok := NewLeaderboardWindow(period, since, since.Location())
+	for ok && w.Closed(now) {
+		windows = append(windows, w)
+		w, ok = NewLeaderboardWindow(period, w.End, since.Location())
+	}
+
+	return windows
+}
+
+// runRecomputeRatings заново считает сохранённые leaderboard по дням/неделям/месяцам,
+// например после ручной правки score_events:
+// warscript-games recompute-ratings -since 2019-05-01 -period week
+func runRecomputeRatings(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("recompute-ratings", flag.ContinueOnError)
+	sinceStr := fs.String("since", "", "дата начала в формате 2006-01-02")
+	period := fs.String("period", "all", "период: day, week, month или all")
+	cfg, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	since, err := time.ParseInLocation("2006-01-02", *sinceStr, cfg.location)
+	if err != nil {
+		return errors.Wrap(err, "-since must be a date like 2006-01-02")
+	}
+
+	periods := []string{WindowDay, WindowWeek, WindowMonth}
+	if *period != "all" {
+		if !IsLeaderboardPeriod(*period) {
+			return errors.Errorf("unknown period %q", *period)
+		}
+		periods = []string{*period}
+	}
+
+	db, err := connectPostgres(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	games := NewAccessObject(db, offlineAuthClient{}, cfg.UsersTimeout)
+	now := time.Now()
+	for _, p := range periods {
+		for _, w := range closedWindows(p, since, now) {
+			if err = games.RecomputeWindow(context.Background(), w); err != nil {
+				return errors.Wrapf(err, "can not recompute %s window %s", p, w.Start.Format("2006-01-02"))
+			}
+			logger.Infof("recomputed %s window %s", p, w.Start.Format("2006-01-02"))
+		}
+	}
+
+	return nil
+}
+
+// runCheckConfig проверяет настройки и все внешние зависимости
+// и сообщает о каждой, не останавливаясь на первой ошибке
+func runCheckConfig(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("check-config", flag.ContinueOnError)
+	cfg, err := parseConfig(fs, args)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("settings: global formula %q, leaderboard timezone %s",
+		cfg.GlobalFormula, cfg.location)
+
+	failed := make([]string, 0)
+	check := func(name string, err error) {
+		if err != nil {
+			logger.Errorf("%s: %s", name, err)
+			failed = append(failed, name)
+			return
+		}
+		logger.Infof("%s: ok", name)
+	}
+
+	consul, err := connectConsul(cfg)
+	if err == nil && consul != nil {
+		_, err = consul.Agent().Self()
+	}
+	if consul != nil || err != nil {
+		check("consul", err)
+	} else {
+		logger.Info("consul: not configured")
+	}
+
+	// без consul порты должны быть в конфиге; с ним их выдаст balancer при старте
+	if consul == nil {
+		_, _, err = getPorts(cfg, nil)
+		check("ports", err)
+	}
+
+	db, err := connectPostgres(cfg)
+	if err == nil {
+		err = db.Ping()
+		check("postgres", err)
+		if err == nil {
+			check("schema version", checkSchemaVersion(db))
+		}
+		//nolint: errcheck
+		db.Close()
+	} else {
+		check("postgres", err)
+	}
+
+	disc, err := newDiscovery(cfg, consul, logger)
+	check(cfg.Discovery+" discovery", err)
+	if err == nil {
+		_, authGPRCConn, err := connectAuth(disc, logger)
+		if err == nil && authGPRCConn != nil {
+			//nolint: errcheck
+			authGPRCConn.Close()
+		}
+		check("warscript-users grpc", err)
+	}
+
+	if len(failed) != 0 {
+		return errors.Errorf("check failed: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
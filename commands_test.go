@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClosedWindows(t *testing.T) {
+	since := time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC)
+	now := time.Date(2019, 5, 23, 1, 0, 0, 0, time.UTC)
+
+	days := closedWindows(WindowDay, since, now)
+	if len(days) != 3 || !days[0].Start.Equal(time.Date(2019, 5, 20, 0, 0, 0, 0, time.UTC)) ||
+		!days[2].End.Equal(time.Date(2019, 5, 23, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("TestClosedWindows got unexpected days: %+v", days)
+	}
+
+	// неделя с понедельника 20 мая ещё не закончилась
+	if weeks := closedWindows(WindowWeek, since, now); len(weeks) != 0 {
+		t.Errorf("TestClosedWindows got unexpected weeks: %+v", weeks)
+	}
+}
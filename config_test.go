@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func loadTestConfig(t *testing.T, args []string, env map[string]string) (*Config, error) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	cf := addConfigFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("can not parse flags: %s", err)
+	}
+
+	return cf.load(fs, func(key string) string {
+		return env[key]
+	})
+}
+
+func TestConfigPrecedence(t *testing.T) {
+	file, err := ioutil.TempFile("", "warscript-games-config")
+	if err != nil {
+		t.Fatalf("can not create config file: %s", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"postgres_dsn": "postgres://file", "http_port": 8080, "grpc_port": 8081,
+		"leaderboard_tz": "Europe/Moscow", "drain_delay": "10s", "admin_ids": [1]}`)
+	if err != nil {
+		t.Fatalf("can not write config file: %s", err)
+	}
+	file.Close()
+
+	cfg, err := loadTestConfig(t, []string{"-http-port", "9090"}, map[string]string{
+		"CONFIG_FILE":    file.Name(),
+		"POSTGRES_DSN":   "postgres://env",
+		"HTTP_PORT":      "7070",
+		"ADMIN_IDS":      "2, 3",
+		"SERVICE_TOKENS": "bots, ,matches",
+	})
+	if err != nil {
+		t.Fatalf("TestConfigPrecedence got unexpected error: %v", err)
+	}
+
+	expected := Config{
+		PostgresDSN:     "postgres://env",
+		HTTPPort:        9090,
+		GRPCPort:        8081,
+		Discovery:       DiscoveryStatic,
+		GlobalFormula:   FormulaRanks,
+		LeaderboardTZ:   "Europe/Moscow",
+		DrainDelay:      Duration(10 * time.Second),
+		ShutdownTimeout: Duration(defaultShutdownTimeout),
+		QueryTimeout:    Duration(defaultQueryTimeout),
+		ExportTimeout:   Duration(defaultExportTimeout),
+		UsersTimeout:    Duration(defaultUsersTimeout),
+		AdminIDs:        []int64{2, 3},
+		ServiceTokens:   []string{"bots", "matches"},
+	}
+	if !reflect.DeepEqual(*cfg, expected) {
+		t.Errorf("TestConfigPrecedence got %+v, expected %+v", *cfg, expected)
+	}
+
+	// без consul нужны порты
+	if _, _, err = getPorts(&Config{HTTPPort: 8080}, nil); err == nil {
+		t.Error("TestConfigPrecedence expected error for missing grpc port")
+	}
+}
+
+func TestConfigInvalid(t *testing.T) {
+	cases := []map[string]string{
+		{"HTTP_PORT": "http"},
+		{"GRPC_PORT": "70000"},
+		{"GLOBAL_LEADERBOARD_FORMULA": "elo"},
+		{"DISCOVERY": "zookeeper"},
+		{"SHUTDOWN_TIMEOUT": "5"},
+		{"QUERY_TIMEOUT": "fast"},
+		{"ADMIN_IDS": "1,root"},
+		{"DISCOVERY_STATIC": "warscript-users-grpc"},
+		{"CONFIG_FILE": "/nonexistent/warscript-games.json"},
+	}
+
+	for i, env := range cases {
+		if _, err := loadTestConfig(t, nil, env); err == nil {
+			t.Errorf("[%d] TestConfigInvalid expected error for %v", i, env)
+		}
+	}
+
+	if _, err := connectPostgres(&Config{}); err == nil {
+		t.Error("TestConfigInvalid expected error for missing postgres")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// queryMethodKey ключ контекста с именем метода DAO: им помечаются
+// все SQL запросы метода
+type queryMethodKey struct{}
+
+// withQueryMethod помечает запросы в ctx именем метода DAO, им называются
+// метрика и спаны запросов:
+// ctx = withQueryMethod(ctx, "GetGameBySlug")
+func withQueryMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, queryMethodKey{}, method)
+}
+
+// queryMethod метод DAO, из которого сделан запрос
+func queryMethod(ctx context.Context) string {
+	if method, ok := ctx.Value(queryMethodKey{}).(string); ok {
+		return method
+	}
+
+	return "unknown"
+}
+
+// queryObserver время и спан одного SQL запроса. Курсор дочитывается уже
+// после QueryContext, а между Next метод может ходить в warscript-users,
+// поэтому время копится только пока мы внутри database/sql. Спан -- сосед
+// похода в warscript-users, а не его родитель: контекст со спаном запроса
+// дальше не передаётся
+type queryObserver struct {
+	method  string
+	elapsed time.Duration
+	sp      *span
+	done    bool
+}
+
+func observeQuery(ctx context.Context, query string) *queryObserver {
+	method := queryMethod(ctx)
+	_, sp := startSpan(ctx, "db."+method, spanClient)
+	sp.SetAttr("db.system", "postgresql")
+	sp.SetAttr("db.operation", method)
+	sp.SetAttr("db.statement", strings.Join(strings.Fields(query), " "))
+
+	return &queryObserver{method: method, sp: sp}
+}
+
+// track добавляет время с start
+func (o *queryObserver) track(start time.Time) {
+	o.elapsed += time.Since(start)
+}
+
+// finish запрос закончен: пишем метрику и закрываем спан с ошибкой err,
+// если она есть. Повторные вызовы ничего не делают
+func (o *queryObserver) finish(err error) {
+	if o.done {
+		return
+	}
+	o.done = true
+	dbDuration.WithLabelValues(o.method).Observe(o.elapsed.Seconds())
+	o.sp.SetError(err)
+	o.sp.End()
+}
+
+// sqlConn общее у *sql.DB и *sql.Tx
+type sqlConn interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func observedQuery(ctx context.Context, conn sqlConn, query string, args []interface{}) (*observedRows, error) {
+	o := observeQuery(ctx, query)
+	start := time.Now()
+	rows, err := conn.QueryContext(ctx, query, args...)
+	o.track(start)
+	if err != nil {
+		o.finish(err)
+		return nil, err
+	}
+
+	return &observedRows{Rows: rows, observer: o}, nil
+}
+
+func observedQueryRow(ctx context.Context, conn sqlConn, query string, args []interface{}) *observedRow {
+	rows, err := observedQuery(ctx, conn, query, args)
+	return &observedRow{rows: rows, err: err}
+}
+
+func observedExec(ctx context.Context, conn sqlConn, query string, args []interface{}) (sql.Result, error) {
+	o := observeQuery(ctx, query)
+	start := time.Now()
+	res, err := conn.ExecContext(ctx, query, args...)
+	o.track(start)
+	o.finish(err)
+
+	return res, err
+}
+
+// observedDB *sql.DB, у которого каждый запрос попадает в db_query_duration_seconds
+// и пишет свой спан
+type observedDB struct {
+	db *sql.DB
+}
+
+func (d *observedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*observedRows, error) {
+	return observedQuery(ctx, d.db, query, args)
+}
+
+func (d *observedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *observedRow {
+	return observedQueryRow(ctx, d.db, query, args)
+}
+
+func (d *observedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return observedExec(ctx, d.db, query, args)
+}
+
+// BeginTx BEGIN тоже поход в базу, считаем его отдельным запросом
+func (d *observedDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*observedTx, error) {
+	o := observeQuery(ctx, "BEGIN")
+	start := time.Now()
+	tx, err := d.db.BeginTx(ctx, opts)
+	o.track(start)
+	o.finish(err)
+	if err != nil {
+		return nil, err
+	}
+
+	return &observedTx{tx: tx, ctx: ctx}, nil
+}
+
+// observedTx *sql.Tx с теми же метриками и спанами. Rollback не считаем: обычно
+// он стоит в defer после Commit и в базу не ходит
+type observedTx struct {
+	tx  *sql.Tx
+	ctx context.Context
+}
+
+func (t *observedTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*observedRows, error) {
+	return observedQuery(ctx, t.tx, query, args)
+}
+
+func (t *observedTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *observedRow {
+	return observedQueryRow(ctx, t.tx, query, args)
+}
+
+func (t *observedTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return observedExec(ctx, t.tx, query, args)
+}
+
+func (t *observedTx) Commit() error {
+	o := observeQuery(t.ctx, "COMMIT")
+	start := time.Now()
+	err := t.tx.Commit()
+	o.track(start)
+	o.finish(err)
+
+	return err
+}
+
+func (t *observedTx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// observedRows курсор, время чтения которого добавляется к запросу.
+// Запрос закончен, когда Next вернул false или курсор закрыли: для выгрузки,
+// которая читает курсор вперемешку с походами в warscript-users, спан
+// запроса накрывает их по времени, но в метрику они не попадают
+type observedRows struct {
+	*sql.Rows
+	observer *queryObserver
+}
+
+func (r *observedRows) Next() bool {
+	start := time.Now()
+	next := r.Rows.Next()
+	r.observer.track(start)
+	if !next {
+		r.observer.finish(r.Rows.Err())
+	}
+
+	return next
+}
+
+func (r *observedRows) Close() error {
+	start := time.Now()
+	err := r.Rows.Close()
+	r.observer.track(start)
+	spanErr := err
+	if spanErr == nil {
+		spanErr = r.Rows.Err()
+	}
+	r.observer.finish(spanErr)
+
+	return err
+}
+
+// observedRow то же, что *sql.Row, но поверх observedRows
+type observedRow struct {
+	rows *observedRows
+	err  error
+}
+
+func (r *observedRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	//nolint: errcheck
+	defer r.rows.Close()
+
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	return r.rows.Close()
+}
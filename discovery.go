@@ -0,0 +1,367 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/balancer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer/roundrobin"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	consulapi "github.com/hashicorp/consul/api"
+)
+
+const (
+	// DiscoveryConsul регистрация и поиск через consul
+	DiscoveryConsul = "consul"
+	// DiscoveryStatic адреса сервисов перечислены в конфиге
+	DiscoveryStatic = "static"
+	// DiscoveryDNS поиск по SRV записям _<service>._tcp.<domain>
+	DiscoveryDNS = "dns"
+)
+
+// usersGRPCService имя сервиса warscript-users в discovery
+const usersGRPCService = "warscript-users-grpc"
+
+// dnsRefreshPeriod как часто перечитываем SRV записи
+const dnsRefreshPeriod = 15 * time.Second
+
+// errServiceNotConfigured сервиса нет в статическом списке
+var errServiceNotConfigured = errors.New("service is not configured")
+
+// ServiceCheck как discovery проверяет, что зарегистрированный сервис жив
+type ServiceCheck struct {
+	// HTTPPath путь readiness проверки http сервиса, например /readyz
+	HTTPPath string
+	// GRPC проверка стандартным gRPC health сервисом
+	GRPC bool
+}
+
+const (
+	// serviceCheckInterval как часто consul дёргает проверку
+	serviceCheckInterval = "10s"
+	// serviceCheckDeregisterAfter инстанс, который так долго не отвечает,
+	// consul удаляет сам: например, после kill -9 без deregister
+	serviceCheckDeregisterAfter = "10m"
+)
+
+// Discovery регистрация наших сервисов и поиск чужих
+type Discovery interface {
+	// Register регистрирует сервис name на порту port с проверкой check
+	// и возвращает его id
+	Register(name string, port int, check *ServiceCheck) (string, error)
+	// Deregister снимает регистрацию, сделанную Register
+	Deregister(id string) error
+	// Dial gRPC соединение с балансировкой по всем инстансам service
+	Dial(service string) (*grpc.ClientConn, error)
+}
+
+// newDiscovery выбирает реализацию Discovery по конфигу
+func newDiscovery(cfg *Config, consul *consulapi.Client, logger *logrus.Logger) (Discovery, error) {
+	switch cfg.Discovery {
+	case DiscoveryConsul:
+		if consul == nil {
+			return nil, errors.New("consul discovery requires CONSUL_ADDR")
+		}
+		return &consulDiscovery{consul: consul}, nil
+	case DiscoveryStatic:
+		services := make(map[string][]string, len(cfg.StaticServices)+1)
+		for name, addrs := range cfg.StaticServices {
+			services[name] = addrs
+		}
+		if cfg.UsersGRPCAddr != "" {
+			services[usersGRPCService] = []string{cfg.UsersGRPCAddr}
+		}
+		return &staticDiscovery{services: services}, nil
+	case DiscoveryDNS:
+		if cfg.DNSDomain == "" {
+			return nil, errors.New("dns discovery requires DISCOVERY_DNS_DOMAIN")
+		}
+		return &dnsDiscovery{domain: cfg.DNSDomain, lookup: net.LookupSRV, logger: logger}, nil
+	}
+
+	return nil, errors.Errorf("unknown discovery %q", cfg.Discovery)
+}
+
+// discoveryScheme схема адресов соединений из dialServers. Builder на неё
+// один на процесс: реестр resolver в grpc глобальный и не чистится
+const discoveryScheme = "warscript"
+
+// discoveryResolvers адреса всех соединений, открытых через dialServers
+var discoveryResolvers = &serversResolverBuilder{targets: make(map[string]*serversResolver)}
+
+func init() {
+	resolver.Register(discoveryResolvers)
+}
+
+// serversResolverBuilder отдаёт каждому соединению его serversResolver по target
+type serversResolverBuilder struct {
+	mu      sync.Mutex
+	nextID  int64
+	targets map[string]*serversResolver
+}
+
+func (b *serversResolverBuilder) Scheme() string {
+	return discoveryScheme
+}
+
+// add заводит resolver с адресами servers и возвращает его вместе с target для grpc.Dial
+func (b *serversResolverBuilder) add(service string, servers []string) (*serversResolver, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	r := &serversResolver{
+		builder: b,
+		key:     fmt.Sprintf("%s-%d", service, b.nextID),
+		done:    make(chan struct{}),
+		addrs:   resolverAddresses(servers),
+	}
+	b.targets[r.key] = r
+
+	return r, discoveryScheme + ":///" + r.key
+}
+
+func (b *serversResolverBuilder) Build(target resolver.Target, cc resolver.ClientConn,
+	opts resolver.BuildOption) (resolver.Resolver, error) {
+	b.mu.Lock()
+	r, ok := b.targets[target.Endpoint]
+	b.mu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("unknown discovery target %q", target.Endpoint)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cc = cc
+	cc.UpdateState(resolver.State{Addresses: r.addrs})
+
+	return r, nil
+}
+
+// serversResolver адреса одного соединения. done закрывается вместе
+// с соединением, по нему останавливается всё, что их обновляет
+type serversResolver struct {
+	builder *serversResolverBuilder
+	key     string
+	done    chan struct{}
+
+	mu    sync.Mutex
+	cc    resolver.ClientConn
+	addrs []resolver.Address
+}
+
+// update отдаёт соединению новый список адресов
+func (r *serversResolver) update(servers []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.addrs = resolverAddresses(servers)
+	if r.cc != nil {
+		r.cc.UpdateState(resolver.State{Addresses: r.addrs})
+	}
+}
+
+func (r *serversResolver) ResolveNow(o resolver.ResolveNowOption) {}
+
+// Close grpc вызывает при закрытии соединения
+func (r *serversResolver) Close() {
+	r.builder.mu.Lock()
+	defer r.builder.mu.Unlock()
+
+	if _, ok := r.builder.targets[r.key]; ok {
+		delete(r.builder.targets, r.key)
+		close(r.done)
+	}
+}
+
+// dialServers соединение с round robin по servers. Список адресов потом
+// можно обновить через возвращённый resolver
+func dialServers(service string, servers []string) (*grpc.ClientConn, *serversResolver, error) {
+	r, target := discoveryResolvers.add(service, servers)
+
+	conn, err := grpc.Dial(target,
+		grpc.WithInsecure(),
+		grpc.WithBalancerName(roundrobin.Name),
+	)
+	if err != nil {
+		r.Close()
+		return nil, nil, errors.Wrapf(err, "can not connect to %s", service)
+	}
+
+	return conn, r, nil
+}
+
+func resolverAddresses(servers []string) []resolver.Address {
+	addrs := make([]resolver.Address, len(servers))
+	for i, server := range servers {
+		addrs[i] = resolver.Address{Addr: server}
+	}
+
+	return addrs
+}
+
+// consulDiscovery как раньше: сервисы в consul, клиенты через balancer
+type consulDiscovery struct {
+	consul *consulapi.Client
+}
+
+func (d *consulDiscovery) Register(name string, port int, check *ServiceCheck) (string, error) {
+	id := fmt.Sprintf("%s:%d", name, port)
+	err := d.consul.Agent().ServiceRegister(&consulapi.AgentServiceRegistration{
+		ID:      id,
+		Name:    name,
+		Port:    port,
+		Address: "127.0.0.1",
+		Check:   consulServiceCheck(port, check),
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "can not register %s", name)
+	}
+
+	return id, nil
+}
+
+// consulServiceCheck проверка consul для нашего сервиса на порту port
+func consulServiceCheck(port int, check *ServiceCheck) *consulapi.AgentServiceCheck {
+	if check == nil {
+		return nil
+	}
+
+	serviceCheck := &consulapi.AgentServiceCheck{
+		Interval:                       serviceCheckInterval,
+		Timeout:                        healthCheckTimeout.String(),
+		DeregisterCriticalServiceAfter: serviceCheckDeregisterAfter,
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	switch {
+	case check.HTTPPath != "":
+		serviceCheck.HTTP = "http://" + addr + check.HTTPPath
+	case check.GRPC:
+		serviceCheck.GRPC = addr
+	default:
+		return nil
+	}
+
+	return serviceCheck
+}
+
+func (d *consulDiscovery) Deregister(id string) error {
+	return d.consul.Agent().ServiceDeregister(id)
+}
+
+func (d *consulDiscovery) Dial(service string) (*grpc.ClientConn, error) {
+	return balancer.ConnectClient(d.consul, service)
+}
+
+// staticDiscovery адреса из конфига; регистрировать нас некуда,
+// этим занимается окружение (docker-compose, k8s service)
+type staticDiscovery struct {
+	services map[string][]string
+}
+
+func (d *staticDiscovery) Register(name string, port int, check *ServiceCheck) (string, error) {
+	return fmt.Sprintf("%s:%d", name, port), nil
+}
+
+func (d *staticDiscovery) Deregister(id string) error {
+	return nil
+}
+
+func (d *staticDiscovery) Dial(service string) (*grpc.ClientConn, error) {
+	servers := d.services[service]
+	if len(servers) == 0 {
+		return nil, errors.Wrap(errServiceNotConfigured, service)
+	}
+
+	conn, _, err := dialServers(service, servers)
+	return conn, err
+}
+
+// dnsDiscovery ищет инстансы по SRV записям и периодически их перечитывает.
+// Регистрацией в DNS занимается окружение
+type dnsDiscovery struct {
+	domain string
+	lookup func(service, proto, name string) (string, []*net.SRV, error)
+	logger *logrus.Logger
+}
+
+func (d *dnsDiscovery) Register(name string, port int, check *ServiceCheck) (string, error) {
+	return fmt.Sprintf("%s:%d", name, port), nil
+}
+
+func (d *dnsDiscovery) Deregister(id string) error {
+	return nil
+}
+
+// resolve адреса инстансов service в стабильном порядке
+func (d *dnsDiscovery) resolve(service string) ([]string, error) {
+	_, records, err := d.lookup(service, "tcp", d.domain)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can not lookup %s SRV records", service)
+	}
+
+	servers := make([]string, 0, len(records))
+	for _, srv := range records {
+		host := strings.TrimSuffix(srv.Target, ".")
+		servers = append(servers, net.JoinHostPort(host, strconv.Itoa(int(srv.Port))))
+	}
+	if len(servers) == 0 {
+		return nil, errors.Errorf("no SRV records for %s", service)
+	}
+	sort.Strings(servers)
+
+	return servers, nil
+}
+
+func (d *dnsDiscovery) Dial(service string) (*grpc.ClientConn, error) {
+	servers, err := d.resolve(service)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, r, err := dialServers(service, servers)
+	if err != nil {
+		return nil, err
+	}
+
+	go d.refresh(service, r, servers)
+
+	return conn, nil
+}
+
+// refresh обновляет адреса в resolver, пока DNS отвечает; при ошибке
+// оставляем последний известный список. Останавливается, когда соединение
+// закрыто: runServe закрывает его после lifecycle.shutdown
+func (d *dnsDiscovery) refresh(service string, r *serversResolver, servers []string) {
+	current := strings.Join(servers, ",")
+	ticker := time.NewTicker(dnsRefreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-r.done:
+			return
+		}
+
+		servers, err := d.resolve(service)
+		if err != nil {
+			d.logger.Errorf("can not refresh %s instances: %s", service, err)
+			continue
+		}
+
+		if next := strings.Join(servers, ","); next != current {
+			r.update(servers)
+			current = next
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseStaticServices(t *testing.T) {
+	cfg := &Config{}
+	err := parseStaticServices(cfg, "warscript-users-grpc=10.0.0.1:9000, 10.0.0.2:9000; warscript-bots=bots:9000;")
+	if err != nil {
+		t.Fatalf("TestParseStaticServices got unexpected error: %v", err)
+	}
+
+	expected := map[string][]string{
+		"warscript-users-grpc": {"10.0.0.1:9000", "10.0.0.2:9000"},
+		"warscript-bots":       {"bots:9000"},
+	}
+	if !reflect.DeepEqual(cfg.StaticServices, expected) {
+		t.Errorf("TestParseStaticServices got %v, expected %v", cfg.StaticServices, expected)
+	}
+}
+
+func TestNewDiscovery(t *testing.T) {
+	cases := []struct {
+		cfg *Config
+		ok  bool
+	}{
+		{cfg: &Config{Discovery: DiscoveryStatic}, ok: true},
+		{cfg: &Config{Discovery: DiscoveryDNS, DNSDomain: "service.consul"}, ok: true},
+		{cfg: &Config{Discovery: DiscoveryDNS}, ok: false},
+		{cfg: &Config{Discovery: DiscoveryConsul}, ok: false},
+		{cfg: &Config{Discovery: "zookeeper"}, ok: false},
+	}
+
+	for i, c := range cases {
+		if _, err := newDiscovery(c.cfg, nil, newTestLogger()); (err == nil) != c.ok {
+			t.Errorf("[%d] TestNewDiscovery got unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestStaticDiscovery(t *testing.T) {
+	disc, err := newDiscovery(&Config{
+		Discovery:     DiscoveryStatic,
+		UsersGRPCAddr: "127.0.0.1:9000",
+	}, nil, newTestLogger())
+	if err != nil {
+		t.Fatalf("TestStaticDiscovery got unexpected error: %v", err)
+	}
+
+	conn, err := disc.Dial(usersGRPCService)
+	if err != nil {
+		t.Fatalf("TestStaticDiscovery got unexpected dial error: %v", err)
+	}
+	conn.Close()
+
+	if _, err = disc.Dial("warscript-bots"); errors.Cause(err) != errServiceNotConfigured {
+		t.Errorf("TestStaticDiscovery got unexpected error: %v, expected: %v", err, errServiceNotConfigured)
+	}
+
+	id, err := disc.Register("warscript-games-http", 8080, &ServiceCheck{HTTPPath: "/readyz"})
+	if err != nil || id != "warscript-games-http:8080" {
+		t.Errorf("TestStaticDiscovery got unexpected register result: %s, %v", id, err)
+	}
+}
+
+func TestDialServersResolver(t *testing.T) {
+	if resolver.Get(discoveryScheme) != discoveryResolvers {
+		t.Fatalf("TestDialServersResolver %s scheme is not registered", discoveryScheme)
+	}
+
+	conn, r, err := dialServers(usersGRPCService, []string{"127.0.0.1:9000"})
+	if err != nil {
+		t.Fatalf("TestDialServersResolver got unexpected error: %v", err)
+	}
+
+	d := &dnsDiscovery{
+		lookup: func(service, proto, name string) (string, []*net.SRV, error) {
+			return "", []*net.SRV{{Target: "users-1.node.consul.", Port: 9000}}, nil
+		},
+		logger: newTestLogger(),
+	}
+	stopped := make(chan struct{})
+	go func() {
+		d.refresh(usersGRPCService, r, []string{"127.0.0.1:9000"})
+		close(stopped)
+	}()
+
+	// resolver и refresh живут ровно столько, сколько соединение
+	conn.Close()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("TestDialServersResolver refresh did not stop after conn.Close")
+	}
+
+	discoveryResolvers.mu.Lock()
+	_, ok := discoveryResolvers.targets[r.key]
+	discoveryResolvers.mu.Unlock()
+	if ok {
+		t.Errorf("TestDialServersResolver resolver %s was not removed after conn.Close", r.key)
+	}
+}
+
+func TestDNSDiscoveryResolve(t *testing.T) {
+	d := &dnsDiscovery{
+		domain: "service.consul",
+		lookup: func(service, proto, name string) (string, []*net.SRV, error) {
+			if service != usersGRPCService || proto != "tcp" || name != "service.consul" {
+				return "", nil, errors.New("no such host")
+			}
+
+			return "", []*net.SRV{
+				{Target: "users-2.node.consul.", Port: 9001},
+				{Target: "users-1.node.consul.", Port: 9000},
+			}, nil
+		},
+	}
+
+	servers, err := d.resolve(usersGRPCService)
+	if err != nil {
+		t.Fatalf("TestDNSDiscoveryResolve got unexpected error: %v", err)
+	}
+
+	expected := []string{"users-1.node.consul:9000", "users-2.node.consul:9001"}
+	if !reflect.DeepEqual(servers, expected) {
+		t.Errorf("TestDNSDiscoveryResolve got %v, expected %v", servers, expected)
+	}
+
+	if _, err = d.resolve("warscript-bots"); err == nil {
+		t.Error("TestDNSDiscoveryResolve expected error for unknown service")
+	}
+}
+
+func TestConsulServiceCheck(t *testing.T) {
+	httpCheck := consulServiceCheck(8080, &ServiceCheck{HTTPPath: "/readyz"})
+	if httpCheck == nil || httpCheck.HTTP != "http://127.0.0.1:8080/readyz" || httpCheck.GRPC != "" {
+		t.Errorf("TestConsulServiceCheck got unexpected http check: %+v", httpCheck)
+	}
+
+	grpcCheck := consulServiceCheck(8081, &ServiceCheck{GRPC: true})
+	if grpcCheck == nil || grpcCheck.GRPC != "127.0.0.1:8081" || grpcCheck.HTTP != "" {
+		t.Errorf("TestConsulServiceCheck got unexpected grpc check: %+v", grpcCheck)
+	}
+
+	if check := consulServiceCheck(8080, nil); check != nil {
+		t.Errorf("TestConsulServiceCheck got unexpected check: %+v", check)
+	}
+}
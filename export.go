@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/mailru/easyjson"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ExportCSV выгрузка leaderboard в CSV с заголовком
+	ExportCSV = "csv"
+	// ExportNDJSON выгрузка leaderboard по JSON объекту на строку
+	ExportNDJSON = "ndjson"
+
+	// exportBatchSize сколько игроков за раз запрашивается у warscript-users
+	exportBatchSize = 500
+)
+
+// exportContentTypes Content-Type для каждого формата выгрузки
+var exportContentTypes = map[string]string{
+	ExportCSV:    "text/csv; charset=utf-8",
+	ExportNDJSON: "application/x-ndjson",
+}
+
+// IsExportFormat проверяет, что такой формат выгрузки поддерживается
+func IsExportFormat(format string) bool {
+	_, ok := exportContentTypes[format]
+	return ok
+}
+
+// exportLeaderboard пишет весь leaderboard игры из games в out в формате format.
+// После каждой пачки вызывается flush (если он есть), чтобы данные
+// уходили клиенту сразу, а не копились в буфере
+func exportLeaderboard(ctx context.Context, games GameAccessObject, slug, format string, out io.Writer, flush func()) error {
+	bw := bufio.NewWriter(out)
+
+	var writeBatch func([]*RankedUserModel) error
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(bw)
+		if err := cw.Write([]string{"rank", "user_id", "username", "score", "active", "photo_uuid"}); err != nil {
+			return errors.Wrap(err, "can not write csv header")
+		}
+
+		writeBatch = func(batch []*RankedUserModel) error {
+			for _, u := range batch {
+				err := cw.Write([]string{
+					strconv.FormatInt(u.Rank, 10),
+					strconv.FormatInt(u.ID, 10),
+					u.Username,
+					strconv.FormatInt(int64(u.Score), 10),
+					strconv.FormatBool(u.Active),
+					u.GetPhotoUUID(),
+				})
+				if err != nil {
+					return errors.Wrap(err, "can not write csv row")
+				}
+			}
+
+			cw.Flush()
+			return cw.Error()
+		}
+	case ExportNDJSON:
+		v := versionFromContext(ctx)
+		writeBatch = func(batch []*RankedUserModel) error {
+			for _, row := range ndjsonRows(v, batch) {
+				if _, err := easyjson.MarshalToWriter(row, bw); err != nil {
+					return errors.Wrap(err, "can not write ndjson row")
+				}
+				if err := bw.WriteByte('\n'); err != nil {
+					return errors.Wrap(err, "can not write ndjson row")
+				}
+			}
+
+			return nil
+		}
+	default:
+		return &utils.ValidationError{
+			"format": utils.ErrInvalid.Error(),
+		}
+	}
+
+	err := games.ExportGameLeaderboard(ctx, slug, exportBatchSize, func(batch []*RankedUserModel) error {
+		if err := writeBatch(batch); err != nil {
+			return err
+		}
+
+		if err := bw.Flush(); err != nil {
+			return errors.Wrap(err, "can not flush export")
+		}
+
+		if flush != nil {
+			flush()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// ndjsonRows строки выгрузки в форме версии v, как в leaderboard этой версии
+func ndjsonRows(v apiVersion, batch []*RankedUserModel) []easyjson.Marshaler {
+	leaders := make([]*jmodels.RankedUser, len(batch))
+	for i, u := range batch {
+		leaders[i] = rankedUserToJSON(u)
+	}
+
+	rows := make([]easyjson.Marshaler, 0, len(batch))
+	switch adapted := adaptResponse(v, leaders).(type) {
+	case []*jmodels.RankedUser:
+		for _, row := range adapted {
+			rows = append(rows, row)
+		}
+	case []*jmodels.RankedUserV2:
+		for _, row := range adapted {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+// ExportGameLeaderboard проходит по всему leaderboard игры курсором и отдаёт
+// его в fn пачками по batchSize игроков, уже с информацией из warscript-users.
+// В памяти одновременно держится только одна пачка
+func (gs *AccessObject) ExportGameLeaderboard(ctx context.Context, slug string, batchSize int,
+	fn func([]*RankedUserModel) error) error {
+	ctx = withQueryMethod(ctx, "ExportGameLeaderboard")
+
+	g, err := gs.getGameImpl(ctx, gs.db, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ErrNotExists
+		}
+
+		return internalError(ctx, "ExportGameLeaderboard can not get game by slug: %v", err)
+	}
+
+	rows, err := gs.db.QueryContext(ctx, `SELECT user_id, score, rank() OVER (ORDER BY score DESC) AS place
+					FROM visible_users_games WHERE game_id = $1
+					ORDER BY score DESC, user_id;`, g.ID)
+	if err != nil {
+		return internalError(ctx, "export leaderboard error: %v", err)
+	}
+	defer rows.Close()
+
+	batch := make([]*RankedUserModel, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := gs.fillRankedUsersInfo(ctx, batch); err != nil {
+			return err
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		batch = make([]*RankedUserModel, 0, batchSize)
+		return nil
+	}
+
+	for rows.Next() {
+		rankedUser := &RankedUserModel{}
+		err = rows.Scan(&rankedUser.ID, &rankedUser.Score, &rankedUser.Rank)
+		if err != nil {
+			return internalError(ctx, "export leaderboard scan user error: %v", err)
+		}
+		// во всём leaderboard место в группе и общее совпадают
+		rankedUser.GlobalRank = rankedUser.Rank
+		batch = append(batch, rankedUser)
+
+		if len(batch) == batchSize {
+			if err = flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return internalError(ctx, "export leaderboard rows error: %v", err)
+	}
+
+	return flush()
+}
+
+func (gs *AccessObject) fillRankedUsersInfo(ctx context.Context, rankedUsers []*RankedUserModel) error {
+	IDs := make([]int64, len(rankedUsers))
+	for i, rankedUser := range rankedUsers {
+		IDs[i] = rankedUser.ID
+	}
+
+	users, err := gs.getUsersInfo(ctx, IDs)
+	if err != nil {
+		return err
+	}
+
+	for _, rankedUser := range rankedUsers {
+		if user, ok := users[rankedUser.ID]; ok {
+			rankedUser.Username = user.Username
+			rankedUser.Active = user.Active
+			rankedUser.PhotoUUID = photoUUIDToNull(user.PhotoUUID)
+		}
+	}
+
+	return nil
+}
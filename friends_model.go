@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/lib/pq"
+)
+
+// RankedUserModel пользователь с местом внутри выбранной группы
+// и местом среди всех игроков
+type RankedUserModel struct {
+	ScoredUserModel
+	Rank       int64
+	GlobalRank int64
+}
+
+// GetGameLeaderboardForUsers leaderboard игры только среди userIDs:
+// Rank считается внутри группы, GlobalRank -- среди всех игроков игры
+func (gs *AccessObject) GetGameLeaderboardForUsers(ctx context.Context, slug string, userIDs []int64,
+	limit, offset int) ([]*RankedUserModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameLeaderboardForUsers")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, internalError(ctx, "can not open GetGameLeaderboardForUsers transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrNotExists
+		}
+
+		return nil, internalError(ctx, "GetGameLeaderboardForUsers can not get game by slug: %v", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT r.user_id, r.score,
+					rank() OVER (ORDER BY r.score DESC) AS place, r.global_place
+					FROM (
+						SELECT ug.user_id, ug.score,
+							rank() OVER (ORDER BY ug.score DESC) AS global_place
+						FROM visible_users_games ug WHERE ug.game_id = $1
+					) r WHERE r.user_id = ANY($2)
+					ORDER BY r.score DESC, r.user_id OFFSET $3 LIMIT $4;`, g.ID, pq.Array(userIDs), offset, limit)
+	if err != nil {
+		return nil, internalError(ctx, "get leaderboard for users error: %v", err)
+	}
+	defer rows.Close()
+
+	leaderboard := make([]*RankedUserModel, 0)
+	for rows.Next() {
+		rankedUser := &RankedUserModel{}
+		err = rows.Scan(&rankedUser.ID, &rankedUser.Score, &rankedUser.Rank, &rankedUser.GlobalRank)
+		if err != nil {
+			return nil, internalError(ctx, "get leaderboard for users scan user error: %v", err)
+		}
+		leaderboard = append(leaderboard, rankedUser)
+	}
+
+	if len(leaderboard) == 0 {
+		return nil, utils.ErrNotExists
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, internalError(ctx, "can not commit GetGameLeaderboardForUsers transaction: %v", err)
+	}
+
+	if err = gs.fillRankedUsersInfo(ctx, leaderboard); err != nil {
+		return nil, err
+	}
+
+	return leaderboard, nil
+}
+
+// GetFollowedUsers ID пользователей, на которых подписан followerID
+func (gs *AccessObject) GetFollowedUsers(ctx context.Context, followerID int64) ([]int64, error) {
+	ctx = withQueryMethod(ctx, "GetFollowedUsers")
+
+	rows, err := gs.db.QueryContext(ctx, `SELECT followee_id FROM follows
+					WHERE follower_id = $1 ORDER BY created_at, followee_id;`, followerID)
+	if err != nil {
+		return nil, internalError(ctx, "get followed users error: %v", err)
+	}
+	defer rows.Close()
+
+	followed := make([]int64, 0)
+	for rows.Next() {
+		var followeeID int64
+		if err = rows.Scan(&followeeID); err != nil {
+			return nil, internalError(ctx, "get followed users scan error: %v", err)
+		}
+		followed = append(followed, followeeID)
+	}
+
+	return followed, nil
+}
+
+// FollowUser подписывает followerID на followeeID; повторная подписка не ошибка
+func (gs *AccessObject) FollowUser(ctx context.Context, followerID, followeeID int64) error {
+	ctx = withQueryMethod(ctx, "FollowUser")
+
+	if followerID == followeeID {
+		return &utils.ValidationError{
+			"followee_id": utils.ErrInvalid.Error(),
+		}
+	}
+
+	_, err := gs.db.ExecContext(ctx, `INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2)
+					ON CONFLICT DO NOTHING;`, followerID, followeeID)
+	if err != nil {
+		return internalError(ctx, "follow user error: %v", err)
+	}
+
+	return nil
+}
+
+// UnfollowUser отписывает followerID от followeeID
+func (gs *AccessObject) UnfollowUser(ctx context.Context, followerID, followeeID int64) error {
+	ctx = withQueryMethod(ctx, "UnfollowUser")
+
+	res, err := gs.db.ExecContext(ctx, `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;`,
+		followerID, followeeID)
+	if err != nil {
+		return internalError(ctx, "unfollow user error: %v", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return internalError(ctx, "unfollow user rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return utils.ErrNotExists
+	}
+
+	return nil
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
+	"io/ioutil"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/HotCodeGroup/warscript-games/jmodels"
 	"github.com/HotCodeGroup/warscript-utils/utils"
@@ -12,32 +14,36 @@ import (
 )
 
 // GetGame получает объект игры
-func GetGame(w http.ResponseWriter, r *http.Request) {
-	logger := utils.GetLogger(r, logger, "GetGame")
-	errWriter := utils.NewErrorResponseWriter(w, logger)
+func (s *Server) GetGame(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGame")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
 	vars := mux.Vars(r)
 
-	game, err := getGameBySlugImpl(vars["game_slug"])
+	game, err := s.getGameBySlugImpl(ctx, vars["game_slug"])
 	if err != nil {
 		if errors.Cause(err) == utils.ErrNotExists {
 			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
 		} else {
-			errWriter.WriteError(http.StatusInternalServerError, errors.Wrap(err, "get game method error"))
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
 		}
 		return
 	}
 
-	utils.WriteApplicationJSON(w, http.StatusOK, game)
+	writeJSON(ctx, w, http.StatusOK, game)
 }
 
 // GetGameList gets list of games
-func GetGameList(w http.ResponseWriter, r *http.Request) {
-	logger := utils.GetLogger(r, logger, "GetGameList")
-	errWriter := utils.NewErrorResponseWriter(w, logger)
+func (s *Server) GetGameList(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGameList")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
 
-	games, err := Games.GetGameList()
+	games, err := s.games.GetGameList(ctx)
 	if err != nil {
-		errWriter.WriteError(http.StatusInternalServerError, errors.Wrap(err, "get game list method error"))
+		writeInternalError(errWriter, errors.Wrap(err, "get game list method error"))
 
 		return
 	}
@@ -51,38 +57,254 @@ func GetGameList(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	utils.WriteApplicationJSON(w, http.StatusOK, respGames)
+	writeJSON(ctx, w, http.StatusOK, respGames)
 }
 
 // GetGameLeaderboard gets list of leaders in game
-func GetGameLeaderboard(w http.ResponseWriter, r *http.Request) {
-	logger := utils.GetLogger(r, logger, "GetGameLeaderboard")
-	errWriter := utils.NewErrorResponseWriter(w, logger)
+func (s *Server) GetGameLeaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGameLeaderboard")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
 	vars := mux.Vars(r)
 
-	query := r.URL.Query()
-	limitParam, err := strconv.Atoi(query.Get("limit"))
+	params := newQueryParams(r)
+	limitParam := params.Limit(defaultLeaderboardLimit)
+	offsetParam := params.Offset()
+
+	if params.String("window") != "" {
+		s.getGameWindowLeaderboard(ctx, w, params, errWriter, limitParam, offsetParam)
+		return
+	}
+
+	if params.String("users") != "" || params.String("followed_by") != "" {
+		s.getGameLeaderboardForUsers(ctx, w, params, errWriter, limitParam, offsetParam)
+		return
+	}
+
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	leadersModels, err := s.games.GetGameLeaderboardBySlug(ctx, vars["game_slug"], limitParam, offsetParam)
 	if err != nil {
-		limitParam = 5
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists or offset is large"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
+		}
+		return
+	}
+
+	leaders := make([]*jmodels.ScoredUser, len(leadersModels))
+	for i, leader := range leadersModels {
+		leaders[i] = scoredUserToJSON(leader)
+	}
+
+	writeJSON(ctx, w, http.StatusOK, leaders)
+}
+
+// getGameLeaderboardForUsers leaderboard только среди ?users=1,2,3
+// и/или подписок пользователя ?followed_by=ID (вместе с ним самим)
+func (s *Server) getGameLeaderboardForUsers(ctx context.Context, w http.ResponseWriter, params *queryParams,
+	errWriter *errorWriter, limit, offset int) {
+	userIDs := params.IDs("users")
+	followerID := params.ID("followed_by")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
 	}
-	offsetParam, err := strconv.Atoi(query.Get("offset"))
+
+	rankedModels, err := s.getLeaderboardForUsersImpl(ctx, params.vars["game_slug"], userIDs, followerID, limit, offset)
 	if err != nil {
-		offsetParam = 0
+		if validErr, ok := errors.Cause(err).(*utils.ValidationError); ok {
+			errWriter.WriteValidationError(validErr)
+		} else if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists or offset is large"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
+		}
+		return
+	}
+
+	ranked := make([]*jmodels.RankedUser, len(rankedModels))
+	for i, rankedUser := range rankedModels {
+		ranked[i] = rankedUserToJSON(rankedUser)
 	}
 
-	leadersModels, err := Games.GetGameLeaderboardBySlug(vars["game_slug"], limitParam, offsetParam)
+	writeJSON(ctx, w, http.StatusOK, ranked)
+}
+
+// getGameWindowLeaderboard leaderboard по очкам, набранным за ?window=day|week|month.
+// По умолчанию текущее окно, ?at=YYYY-MM-DD выбирает окно, в которое попадает эта дата
+func (s *Server) getGameWindowLeaderboard(ctx context.Context, w http.ResponseWriter, params *queryParams,
+	errWriter *errorWriter, limit, offset int) {
+	at := params.Date("at", time.Now(), s.location)
+	period := params.OneOf("window", "", IsLeaderboardPeriod)
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+	window, _ := NewLeaderboardWindow(period, at, s.location)
+
+	leadersModels, err := s.games.GetGameWindowLeaderboardBySlug(ctx, params.vars["game_slug"], window, limit, offset)
 	if err != nil {
 		if errors.Cause(err) == utils.ErrNotExists {
 			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists or offset is large"))
 		} else {
-			errWriter.WriteError(http.StatusInternalServerError, errors.Wrap(err, "get game method error"))
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
 		}
 		return
 	}
 
-	leaders := make([]*jmodels.ScoredUser, len(leadersModels))
+	leaders := make([]*jmodels.WindowLeader, len(leadersModels))
+	for i, leader := range leadersModels {
+		leaders[i] = &jmodels.WindowLeader{
+			InfoUser: jmodels.InfoUser{
+				BasicUser: jmodels.BasicUser{
+					Username:  leader.Username,
+					PhotoUUID: leader.GetPhotoUUID(),
+				},
+				ID:     leader.ID,
+				Active: leader.Active,
+			},
+			Points: leader.Points,
+			Rank:   leader.Rank,
+		}
+	}
+
+	writeJSON(ctx, w, http.StatusOK, leaders)
+}
+
+func rankedUserToJSON(user *RankedUserModel) *jmodels.RankedUser {
+	return &jmodels.RankedUser{
+		ScoredUser: *scoredUserToJSON(&user.ScoredUserModel),
+		Rank:       user.Rank,
+		GlobalRank: user.GlobalRank,
+	}
+}
+
+func scoredUserToJSON(user *ScoredUserModel) *jmodels.ScoredUser {
+	return &jmodels.ScoredUser{
+		InfoUser: jmodels.InfoUser{
+			BasicUser: jmodels.BasicUser{
+				Username:  user.Username,
+				PhotoUUID: user.GetPhotoUUID(),
+			},
+			ID:     user.ID,
+			Active: user.Active,
+		},
+		Score: user.Score,
+	}
+}
+
+// GetFollowedUsers список ID пользователей, на которых подписан user_id
+func (s *Server) GetFollowedUsers(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetFollowedUsers")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	followerID := params.PathID("user_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	followed, err := s.games.GetFollowedUsers(ctx, followerID)
+	if err != nil {
+		writeInternalError(errWriter, errors.Wrap(err, "get followed users method error"))
+		return
+	}
+
+	writeJSON(ctx, w, http.StatusOK, followed)
+}
+
+// FollowUser подписка user_id на followee_id
+func (s *Server) FollowUser(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "FollowUser")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+
+	params := newQueryParams(r)
+	followerID, followeeID := params.PathID("user_id"), params.PathID("followee_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	err := s.games.FollowUser(ctx, followerID, followeeID)
+	if err != nil {
+		if validErr, ok := errors.Cause(err).(*utils.ValidationError); ok {
+			errWriter.WriteValidationError(validErr)
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "follow user method error"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnfollowUser отписка user_id от followee_id
+func (s *Server) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "UnfollowUser")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+
+	params := newQueryParams(r)
+	followerID, followeeID := params.PathID("user_id"), params.PathID("followee_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	err := s.games.UnfollowUser(ctx, followerID, followeeID)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "follow not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "unfollow user method error"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetGlobalLeaderboard общий рейтинг игроков по всем играм
+func (s *Server) GetGlobalLeaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGlobalLeaderboard")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+
+	params := newQueryParams(r)
+	limitParam := params.Limit(defaultLeaderboardLimit)
+	offsetParam := params.Offset()
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	leadersModels, err := s.getGlobalLeaderboardImpl(ctx, params.String("formula"), limitParam, offsetParam)
+	if err != nil {
+		if validErr, ok := errors.Cause(err).(*utils.ValidationError); ok {
+			errWriter.WriteValidationError(validErr)
+		} else if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "no players or offset is large"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get global leaderboard method error"))
+		}
+		return
+	}
+
+	leaders := make([]*jmodels.GlobalLeader, len(leadersModels))
 	for i, leader := range leadersModels {
-		leaders[i] = &jmodels.ScoredUser{
+		leaders[i] = &jmodels.GlobalLeader{
 			InfoUser: jmodels.InfoUser{
 				BasicUser: jmodels.BasicUser{
 					Username:  leader.Username,
@@ -91,32 +313,370 @@ func GetGameLeaderboard(w http.ResponseWriter, r *http.Request) {
 				ID:     leader.ID,
 				Active: leader.Active,
 			},
-			Score: leader.Score,
+			Rating:      leader.Rating,
+			Rank:        leader.Rank,
+			GamesPlayed: leader.GamesPlayed,
+		}
+	}
+
+	writeJSON(ctx, w, http.StatusOK, leaders)
+}
+
+// GetUserGames игры, в которые играл пользователь, и его места в них
+func (s *Server) GetUserGames(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetUserGames")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	userID := params.PathID("user_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	userGamesModels, err := s.games.GetUserGames(ctx, userID)
+	if err != nil {
+		writeInternalError(errWriter, errors.Wrap(err, "get user games method error"))
+		return
+	}
+
+	userGames := make([]*jmodels.UserGame, len(userGamesModels))
+	for i, ug := range userGamesModels {
+		userGames[i] = &jmodels.UserGame{
+			Game: jmodels.Game{
+				Slug:           ug.Slug,
+				Title:          ug.Title,
+				BackgroundUUID: ug.GetBackgroundUUID(),
+			},
+			Score:      ug.Score,
+			Rank:       ug.Rank,
+			Percentile: ug.Percentile,
+			LastPlayed: ug.LastPlayed,
+		}
+	}
+
+	writeJSON(ctx, w, http.StatusOK, userGames)
+}
+
+// GetUserScoreEvents история изменений очков пользователя в игре (для админов)
+func (s *Server) GetUserScoreEvents(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetUserScoreEvents")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	userID := params.PathID("user_id")
+	limitParam := params.Limit(defaultEventsLimit)
+	offsetParam := params.Offset()
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	eventsModels, err := s.games.GetUserScoreEvents(ctx, params.vars["game_slug"], userID, limitParam, offsetParam)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get score events method error"))
+		}
+		return
+	}
+
+	events := make([]*jmodels.ScoreEvent, len(eventsModels))
+	for i, e := range eventsModels {
+		events[i] = &jmodels.ScoreEvent{
+			ID:        e.ID,
+			UserID:    e.UserID,
+			OldScore:  e.OldScore,
+			NewScore:  e.NewScore,
+			Reason:    e.Reason,
+			Source:    e.Source,
+			MatchID:   e.GetMatchID(),
+			CreatedAt: e.CreatedAt,
+		}
+	}
+
+	writeJSON(ctx, w, http.StatusOK, events)
+}
+
+// ExportGameLeaderboard отдаёт весь leaderboard игры потоком в ?format=csv|ndjson
+func (s *Server) ExportGameLeaderboard(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "ExportGameLeaderboard")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.exportContext(r.Context())
+	defer cancel()
+	vars := mux.Vars(r)
+
+	params := newQueryParams(r)
+	format := params.OneOf("format", ExportCSV, IsExportFormat)
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	// проверяем игру заранее, пока ещё можно ответить 404
+	if _, err := s.games.GetGameBySlug(ctx, vars["game_slug"]); err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "export leaderboard method error"))
 		}
+		return
+	}
+
+	var flush func()
+	if flusher, ok := w.(http.Flusher); ok {
+		flush = flusher.Flush
 	}
 
-	utils.WriteApplicationJSON(w, http.StatusOK, leaders)
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", `attachment; filename="`+vars["game_slug"]+`-leaderboard.`+format+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	// заголовки уже отправлены, так что об ошибке можно только залогировать
+	if err := exportLeaderboard(ctx, s.games, vars["game_slug"], format, w, flush); err != nil {
+		logger.Error(errors.Wrap(err, "export leaderboard interrupted"))
+	}
+}
+
+const (
+	defaultStatsBuckets = 10
+	maxStatsBuckets     = 100
+)
+
+// GetGameScoreStats перцентили и гистограмма очков игроков
+func (s *Server) GetGameScoreStats(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGameScoreStats")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	vars := mux.Vars(r)
+
+	params := newQueryParams(r)
+	bucketsParam := params.Int("buckets", defaultStatsBuckets, 1, maxStatsBuckets)
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	stats, err := s.getGameScoreStatsImpl(ctx, vars["game_slug"], bucketsParam)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get game score stats method error"))
+		}
+		return
+	}
+
+	writeJSON(ctx, w, http.StatusOK, stats)
 }
 
 // GetGameTotalPlayers количество юзеров игравших в game_id
-func GetGameTotalPlayers(w http.ResponseWriter, r *http.Request) {
-	logger := utils.GetLogger(r, logger, "GetGameTotalPlayers")
-	errWriter := utils.NewErrorResponseWriter(w, logger)
+func (s *Server) GetGameTotalPlayers(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetGameTotalPlayers")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
 	vars := mux.Vars(r)
 
-	totalCount, err := Games.GetGameTotalPlayersBySlug(vars["game_slug"])
+	totalCount, err := s.games.GetGameTotalPlayersBySlug(ctx, vars["game_slug"])
 	if err != nil {
 		if errors.Cause(err) == utils.ErrNotExists {
 			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
 		} else {
-			errWriter.WriteError(http.StatusInternalServerError, errors.Wrap(err, "get game method error"))
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
 		}
 		return
 	}
 
-	utils.WriteApplicationJSON(w, http.StatusOK, &struct {
-		Count int64 `json:"count"`
-	}{
+	writeJSON(ctx, w, http.StatusOK, &jmodels.PlayersCount{
 		Count: totalCount,
 	})
 }
+
+// UpdateGame меняет описание игры: авторам игры и admin
+func (s *Server) UpdateGame(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "UpdateGame")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	slug := mux.Vars(r)["game_slug"]
+
+	if err := authorize(ctx, permEditGame, slug); err != nil {
+		writeAuthError(errWriter, err)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		errWriter.WriteWarn(http.StatusBadRequest, errors.Wrap(err, "can not read body"))
+		return
+	}
+	update := &jmodels.GameUpdate{}
+	if err = update.UnmarshalJSON(body); err != nil {
+		errWriter.WriteValidationError(&utils.ValidationError{"body": utils.ErrInvalid.Error()})
+		return
+	}
+
+	game, err := s.games.GetGameBySlug(ctx, slug)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "get game method error"))
+		}
+		return
+	}
+
+	updated := *game
+	if validErr := applyGameUpdate(&updated, update); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+	if _, err = s.games.UpsertGame(ctx, &updated); err != nil {
+		writeInternalError(errWriter, errors.Wrap(err, "update game method error"))
+		return
+	}
+
+	writeJSON(ctx, w, http.StatusOK, gameFullToJSON(&updated))
+}
+
+// HideUserScore скрывает очки user_id в игре из leaderboard и статистики
+func (s *Server) HideUserScore(w http.ResponseWriter, r *http.Request) {
+	s.setUserScoreHidden(w, r, true, "HideUserScore")
+}
+
+// ShowUserScore возвращает скрытые очки user_id обратно
+func (s *Server) ShowUserScore(w http.ResponseWriter, r *http.Request) {
+	s.setUserScoreHidden(w, r, false, "ShowUserScore")
+}
+
+func (s *Server) setUserScoreHidden(w http.ResponseWriter, r *http.Request, hidden bool, method string) {
+	logger := utils.GetLogger(r, s.logger, method)
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	slug := params.vars["game_slug"]
+
+	if err := authorize(ctx, permModerateScores, slug); err != nil {
+		writeAuthError(errWriter, err)
+		return
+	}
+
+	userID := params.PathID("user_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	err := s.games.SetScoreHidden(ctx, slug, userID, hidden)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game or player not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "set score hidden method error"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetUserGrants роли пользователя user_id
+func (s *Server) GetUserGrants(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "GetUserGrants")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	userID := params.PathID("user_id")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	grantModels, err := s.games.GetUserGrants(ctx, userID)
+	if err != nil {
+		writeInternalError(errWriter, errors.Wrap(err, "get user grants method error"))
+		return
+	}
+
+	grants := make([]*jmodels.Grant, len(grantModels))
+	for i, g := range grantModels {
+		grants[i] = grantToJSON(g)
+	}
+
+	writeJSON(ctx, w, http.StatusOK, grants)
+}
+
+// AddGrant выдаёт user_id роль role на игру ?game= или на все игры.
+// Повторная выдача ничего не меняет
+func (s *Server) AddGrant(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "AddGrant")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	userID := params.PathID("user_id")
+	role, slug := params.vars["role"], params.String("game")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+	if validErr := validateGrant(role, slug); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	_, err := s.games.AddGrant(ctx, &GrantModel{
+		UserID:    userID,
+		GameSlug:  slug,
+		Role:      role,
+		GrantedBy: userFromContext(ctx).ID,
+	})
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "game not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "add grant method error"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeleteGrant забирает у user_id роль role на игру ?game= или на все игры
+func (s *Server) DeleteGrant(w http.ResponseWriter, r *http.Request) {
+	logger := utils.GetLogger(r, s.logger, "DeleteGrant")
+	errWriter := newErrorWriter(w, logger)
+	ctx, cancel := s.queryContext(r.Context())
+	defer cancel()
+	params := newQueryParams(r)
+	userID := params.PathID("user_id")
+	role, slug := params.vars["role"], params.String("game")
+	if validErr := params.Err(); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+	if validErr := validateGrant(role, slug); validErr != nil {
+		errWriter.WriteValidationError(validErr)
+		return
+	}
+
+	err := s.games.DeleteGrant(ctx, userID, slug, role)
+	if err != nil {
+		if errors.Cause(err) == utils.ErrNotExists {
+			errWriter.WriteWarn(http.StatusNotFound, errors.Wrap(err, "grant not exists"))
+		} else {
+			writeInternalError(errWriter, errors.Wrap(err, "delete grant method error"))
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,14 +1,26 @@
 package main
 
-import "github.com/HotCodeGroup/warscript-games/jmodels"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
 
-func getGameBySlugImpl(slug string) (*jmodels.GameFull, error) {
-	game, err := Games.GetGameBySlug(slug)
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+func (s *Server) getGameBySlugImpl(ctx context.Context, slug string) (*jmodels.GameFull, error) {
+	game, err := s.games.GetGameBySlug(ctx, slug)
 
 	if err != nil {
 		return nil, err
 	}
 
+	return gameFullToJSON(game), nil
+}
+
+func gameFullToJSON(game *GameModel) *jmodels.GameFull {
 	return &jmodels.GameFull{
 		Game: jmodels.Game{
 			Slug:           game.Slug,
@@ -20,5 +32,141 @@ func getGameBySlugImpl(slug string) (*jmodels.GameFull, error) {
 		CodeExample: game.CodeExample,
 		BotCode:     game.BotCode,
 		LogoUUID:    game.GetLogoUUID(), // точно 16 байт
-	}, nil
+	}
+}
+
+// applyGameUpdate переносит в g поля из u, которые есть в запросе,
+// и проверяет их так же, как определения игр из файлов
+func applyGameUpdate(g *GameModel, u *jmodels.GameUpdate) *utils.ValidationError {
+	validErr := utils.ValidationError{}
+	required := func(field string, dst *string, v *string) {
+		if v == nil {
+			return
+		}
+		if strings.TrimSpace(*v) == "" {
+			validErr[field] = utils.ErrRequired.Error()
+			return
+		}
+		*dst = *v
+	}
+	uuid := func(field string, dst *sql.NullString, v *string) {
+		if v == nil {
+			return
+		}
+		if !uuidRe.MatchString(*v) {
+			validErr[field] = utils.ErrInvalid.Error()
+			return
+		}
+		*dst = sql.NullString{String: *v, Valid: true}
+	}
+
+	required("title", &g.Title, u.Title)
+	if u.Description != nil {
+		g.Description = *u.Description
+	}
+	required("rules", &g.Rules, u.Rules)
+	required("code_example", &g.CodeExample, u.CodeExample)
+	required("bot_code", &g.BotCode, u.BotCode)
+	uuid("logo_uuid", &g.LogoUUID, u.LogoUUID)
+	uuid("background_uuid", &g.BackgroundUUID, u.BackgroundUUID)
+
+	if len(validErr) != 0 {
+		return &validErr
+	}
+
+	return nil
+}
+
+func grantToJSON(g *GrantModel) *jmodels.Grant {
+	return &jmodels.Grant{
+		ID:        g.ID,
+		UserID:    g.UserID,
+		GameSlug:  g.GameSlug,
+		Role:      g.Role,
+		GrantedBy: g.GrantedBy,
+		CreatedAt: g.CreatedAt,
+	}
+}
+
+func (s *Server) getGameScoreStatsImpl(ctx context.Context, slug string, buckets int) (*jmodels.ScoreStats, error) {
+	cacheKey := fmt.Sprintf("%s:%d", strings.ToLower(slug), buckets)
+	cached, ok := s.statsCache.Get(cacheKey)
+	observeStatsCache(ok)
+	if ok {
+		return cached.(*jmodels.ScoreStats), nil
+	}
+
+	stats, err := s.games.GetGameScoreStatsBySlug(ctx, slug, buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	respBuckets := make([]*jmodels.ScoreBucket, len(stats.Buckets))
+	for i, bucket := range stats.Buckets {
+		respBuckets[i] = &jmodels.ScoreBucket{
+			From:  bucket.From,
+			To:    bucket.To,
+			Count: bucket.Count,
+		}
+	}
+
+	resp := &jmodels.ScoreStats{
+		Count:   stats.Count,
+		Min:     stats.Min,
+		Max:     stats.Max,
+		P50:     stats.P50,
+		P90:     stats.P90,
+		P99:     stats.P99,
+		Buckets: respBuckets,
+	}
+	s.statsCache.Set(cacheKey, resp)
+
+	return resp, nil
+}
+
+func (s *Server) getGlobalLeaderboardImpl(ctx context.Context, formula string, limit, offset int) ([]*GlobalScoredUserModel, error) {
+	if formula == "" {
+		formula = s.globalFormula
+	}
+
+	if !IsGlobalFormula(formula) {
+		return nil, &utils.ValidationError{
+			"formula": utils.ErrInvalid.Error(),
+		}
+	}
+
+	return s.games.GetGlobalLeaderboard(ctx, formula, limit, offset)
+}
+
+// maxLeaderboardUsers ограничение на размер группы в leaderboard по друзьям
+const maxLeaderboardUsers = 1000
+
+func (s *Server) getLeaderboardForUsersImpl(ctx context.Context, slug string, userIDs []int64, followerID int64,
+	limit, offset int) ([]*RankedUserModel, error) {
+	if followerID != 0 {
+		followed, err := s.games.GetFollowedUsers(ctx, followerID)
+		if err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, followerID)
+		userIDs = append(userIDs, followed...)
+	}
+
+	uniqueIDs := make([]int64, 0, len(userIDs))
+	seen := make(map[int64]struct{}, len(userIDs))
+	for _, id := range userIDs {
+		if _, ok := seen[id]; !ok {
+			seen[id] = struct{}{}
+			uniqueIDs = append(uniqueIDs, id)
+		}
+	}
+
+	if len(uniqueIDs) == 0 || len(uniqueIDs) > maxLeaderboardUsers {
+		return nil, &utils.ValidationError{
+			"users": utils.ErrInvalid.Error(),
+		}
+	}
+
+	return s.games.GetGameLeaderboardForUsers(ctx, slug, uniqueIDs, limit, offset)
 }
@@ -3,35 +3,57 @@ package main
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/HotCodeGroup/warscript-utils/models"
-	"github.com/HotCodeGroup/warscript-utils/postgresql"
 	"github.com/HotCodeGroup/warscript-utils/utils"
 
-	"github.com/pkg/errors"
-
 	// драйвер Database
 	_ "github.com/lib/pq"
 )
 
-var pqConn *sql.DB
-
 // GameAccessObject DAO for User model
 type GameAccessObject interface {
-	GetGameBySlug(slug string) (*GameModel, error)
-	GetGameTotalPlayersBySlug(slug string) (int64, error)
-	GetGameList() ([]*GameModel, error)
-	GetGameLeaderboardBySlug(slug string, limit, offset int) ([]*ScoredUserModel, error)
+	GetGameBySlug(ctx context.Context, slug string) (*GameModel, error)
+	GetGameTotalPlayersBySlug(ctx context.Context, slug string) (int64, error)
+	GetGameList(ctx context.Context) ([]*GameModel, error)
+	GetGameLeaderboardBySlug(ctx context.Context, slug string, limit, offset int) ([]*ScoredUserModel, error)
+	GetGameScoreStatsBySlug(ctx context.Context, slug string, buckets int) (*ScoreStatsModel, error)
+	GetGlobalLeaderboard(ctx context.Context, formula string, limit, offset int) ([]*GlobalScoredUserModel, error)
+	GetUserGames(ctx context.Context, userID int64) ([]*UserGameModel, error)
+	GetGameLeaderboardForUsers(ctx context.Context, slug string, userIDs []int64, limit, offset int) ([]*RankedUserModel, error)
+	GetFollowedUsers(ctx context.Context, followerID int64) ([]int64, error)
+	FollowUser(ctx context.Context, followerID, followeeID int64) error
+	UnfollowUser(ctx context.Context, followerID, followeeID int64) error
+	GetGameWindowLeaderboardBySlug(ctx context.Context, slug string, w *LeaderboardWindow, limit, offset int) ([]*WindowScoredUserModel, error)
+	SnapshotWindow(ctx context.Context, w *LeaderboardWindow) error
+	GetUserScoreEvents(ctx context.Context, slug string, userID int64, limit, offset int) ([]*ScoreEventModel, error)
+	UpdateUserScore(ctx context.Context, slug string, userID int64, change *ScoreChange) (int32, int32, error)
+	ExportGameLeaderboard(ctx context.Context, slug string, batchSize int, fn func([]*RankedUserModel) error) error
+	UpsertGame(ctx context.Context, g *GameModel) (bool, error)
+	RecomputeWindow(ctx context.Context, w *LeaderboardWindow) error
+	GetUserGrants(ctx context.Context, userID int64) ([]*GrantModel, error)
+	AddGrant(ctx context.Context, g *GrantModel) (bool, error)
+	DeleteGrant(ctx context.Context, userID int64, slug, role string) error
+	SetScoreHidden(ctx context.Context, slug string, userID int64, hidden bool) error
 }
 
 // AccessObject implementation of GameAccessObject
-type AccessObject struct{}
-
-// Games interface variable for models methods
-var Games GameAccessObject
+type AccessObject struct {
+	db *observedDB
+	// auth warscript-users, из него берём имена и аватарки игроков
+	auth models.AuthClient
+	// usersTimeout таймаут похода в auth; 0 -- только дедлайн запроса
+	usersTimeout Duration
+}
 
-func init() {
-	Games = &AccessObject{}
+// NewAccessObject DAO поверх соединения с postgres
+func NewAccessObject(db *sql.DB, auth models.AuthClient, usersTimeout Duration) *AccessObject {
+	return &AccessObject{
+		db:           &observedDB{db: db},
+		auth:         auth,
+		usersTimeout: usersTimeout,
+	}
 }
 
 // GameModel модель для таблицы games
@@ -84,113 +106,155 @@ func (u *ScoredUserModel) GetPhotoUUID() string {
 }
 
 // GetGameBySlug получает информацию об игре по slug
-func (gs *AccessObject) GetGameBySlug(slug string) (*GameModel, error) {
-	g, err := gs.getGameImpl(pqConn, "slug", slug)
+func (gs *AccessObject) GetGameBySlug(ctx context.Context, slug string) (*GameModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameBySlug")
+
+	g, err := gs.getGameImpl(ctx, gs.db, "slug", slug)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, utils.ErrNotExists
 		}
 
-		return nil, errors.Wrapf(utils.ErrInternal, "get game by slug error: %s", err.Error())
+		return nil, internalError(ctx, "get game by slug error: %s", err.Error())
 	}
 
 	return g, nil
 }
 
 // GetGameTotalPlayersBySlug получение общего количества игроков
-func (gs *AccessObject) GetGameTotalPlayersBySlug(slug string) (int64, error) {
-	tx, err := pqConn.Begin()
+func (gs *AccessObject) GetGameTotalPlayersBySlug(ctx context.Context, slug string) (int64, error) {
+	ctx = withQueryMethod(ctx, "GetGameTotalPlayersBySlug")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
 	if err != nil {
-		return 0, errors.Wrapf(utils.ErrInternal, "can not open GetGameTotalPlayersByID transaction: %v", err)
+		return 0, internalError(ctx, "can not open GetGameTotalPlayersByID transaction: %v", err)
 	}
 
 	//nolint: errcheck
 	defer tx.Rollback()
 
-	g, err := gs.getGameImpl(tx, "slug", slug)
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return 0, utils.ErrNotExists
 		}
 
-		return 0, errors.Wrapf(utils.ErrInternal, "GetGameTotalPlayersByID can not get game by id: %v", err)
+		return 0, internalError(ctx, "GetGameTotalPlayersByID can not get game by id: %v", err)
 	}
 
 	var totalPlayers int64
-	row := tx.QueryRow(`SELECT count(*) FROM users_games WHERE game_id = $1;`, &g.ID)
+	row := tx.QueryRowContext(ctx, `SELECT count(*) FROM visible_users_games WHERE game_id = $1;`, &g.ID)
 	if err = row.Scan(&totalPlayers); err != nil {
-		return 0, errors.Wrapf(utils.ErrInternal, "get game total players error: %v", err)
+		return 0, internalError(ctx, "get game total players error: %v", err)
 	}
 
 	err = tx.Commit()
 	if err != nil {
-		return 0, errors.Wrapf(utils.ErrInternal, "can not commit GetGameTotalPlayersByID transaction: %v", err)
+		return 0, internalError(ctx, "can not commit GetGameTotalPlayersByID transaction: %v", err)
 	}
 
 	return totalPlayers, nil
 }
 
 // GetGameLeaderboardBySlug получаем leaderboard по slug
-func (gs *AccessObject) GetGameLeaderboardBySlug(slug string, limit, offset int) ([]*ScoredUserModel, error) {
+func (gs *AccessObject) GetGameLeaderboardBySlug(ctx context.Context, slug string, limit, offset int) ([]*ScoredUserModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameLeaderboardBySlug")
+
 	// узнаём количество
 
-	rows, err := pqConn.Query(`SELECT ug.user_id, ug.score FROM users_games ug
+	rows, err := gs.db.QueryContext(ctx, `SELECT ug.user_id, ug.score FROM visible_users_games ug
 					RIGHT JOIN games g on ug.game_id = g.id
 					WHERE g.slug = $1 ORDER BY ug.score DESC OFFSET $2 LIMIT $3;`, slug, offset, limit)
 	if err != nil {
-		return nil, errors.Wrapf(utils.ErrInternal, "get leaderboard error: %v", err)
+		return nil, internalError(ctx, "get leaderboard error: %v", err)
 	}
 	defer rows.Close()
 
-	IDs := make([]*models.UserID, 0)
+	IDs := make([]int64, 0)
 	leaderboard := make([]*ScoredUserModel, 0)
 	for rows.Next() {
 		scoredUser := &ScoredUserModel{}
 		err = rows.Scan(&scoredUser.ID, &scoredUser.Score)
 		if err != nil {
-			return nil, errors.Wrapf(utils.ErrInternal, "get leaderboard scan user error: %v", err)
+			return nil, internalError(ctx, "get leaderboard scan user error: %v", err)
 		}
 		leaderboard = append(leaderboard, scoredUser)
-		IDs = append(IDs, &models.UserID{
-			ID: scoredUser.ID,
-		})
+		IDs = append(IDs, scoredUser.ID)
 	}
 
 	if len(leaderboard) == 0 {
 		return nil, utils.ErrNotExists
 	}
 
-	users, err := authGPRC.GetUsersByIDs(context.Background(), &models.UserIDs{
-		IDs: IDs,
-	})
-
+	users, err := gs.getUsersInfo(ctx, IDs)
 	if err != nil {
-		return nil, errors.Wrapf(utils.ErrInternal, "can't connect to auth service to get users error: %v", err)
+		return nil, err
 	}
 
-	for i := 0; i < len(leaderboard); i++ {
-		leaderboard[i].Username = users.Users[i].Username
-		leaderboard[i].Active = users.Users[i].Active
-
-		if users.Users[i].PhotoUUID == "" {
-			leaderboard[i].PhotoUUID.Valid = false
-		} else {
-			leaderboard[i].PhotoUUID.String = users.Users[i].PhotoUUID
-			leaderboard[i].PhotoUUID.Valid = true
+	for _, scoredUser := range leaderboard {
+		if user, ok := users[scoredUser.ID]; ok {
+			scoredUser.Username = user.Username
+			scoredUser.Active = user.Active
+			scoredUser.PhotoUUID = photoUUIDToNull(user.PhotoUUID)
 		}
 	}
 
 	return leaderboard, nil
 }
 
+// getUsersInfo ходит в warscript-users за информацией о пользователях
+// и возвращает её в виде map по ID пользователя
+func (gs *AccessObject) getUsersInfo(ctx context.Context, IDs []int64) (map[int64]*models.InfoUser, error) {
+	reqIDs := make([]*models.UserID, len(IDs))
+	for i, id := range IDs {
+		reqIDs[i] = &models.UserID{
+			ID: id,
+		}
+	}
+
+	ctx, cancel := withTimeout(outgoingRequestID(ctx), gs.usersTimeout)
+	defer cancel()
+
+	ctx, sp := startSpan(ctx, "warscript-users/GetUsersByIDs", spanClient)
+	sp.SetAttr("rpc.system", "grpc")
+	sp.SetAttr("users.count", len(IDs))
+	start := time.Now()
+	users, err := gs.auth.GetUsersByIDs(outgoingTraceparent(ctx), &models.UserIDs{
+		IDs: reqIDs,
+	})
+	observeUsersCall(start, err)
+	sp.SetError(err)
+	sp.End()
+	if err != nil {
+		return nil, internalError(ctx, "can't connect to auth service to get users error: %v", err)
+	}
+
+	usersByID := make(map[int64]*models.InfoUser, len(users.Users))
+	for _, user := range users.Users {
+		usersByID[user.ID] = user
+	}
+
+	return usersByID, nil
+}
+
+func photoUUIDToNull(photoUUID string) sql.NullString {
+	if photoUUID == "" {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: photoUUID, Valid: true}
+}
+
 // GetGameList returns full list of active games
-func (gs *AccessObject) GetGameList() ([]*GameModel, error) {
-	rows, err := pqConn.Query(`SELECT g.id, g.slug, g.title, g.description,
+func (gs *AccessObject) GetGameList(ctx context.Context) ([]*GameModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameList")
+
+	rows, err := gs.db.QueryContext(ctx, `SELECT g.id, g.slug, g.title, g.description,
 								g.rules, g.code_example, g.bot_code, g.logo_uuid, g.background_uuid
 								FROM games g ORDER BY g.id`)
 	if err != nil {
-		return nil, errors.Wrapf(utils.ErrInternal, "get game list error: %v", err)
+		return nil, internalError(ctx, "get game list error: %v", err)
 	}
 	defer rows.Close()
 
@@ -200,7 +264,7 @@ func (gs *AccessObject) GetGameList() ([]*GameModel, error) {
 		err = rows.Scan(&g.ID, &g.Slug, &g.Title, &g.Description,
 			&g.Rules, &g.CodeExample, &g.BotCode, &g.LogoUUID, &g.BackgroundUUID)
 		if err != nil {
-			return nil, errors.Wrapf(utils.ErrInternal, "get games scan game error: %v", err)
+			return nil, internalError(ctx, "get games scan game error: %v", err)
 		}
 		games = append(games, g)
 	}
@@ -208,11 +272,16 @@ func (gs *AccessObject) GetGameList() ([]*GameModel, error) {
 	return games, nil
 }
 
-func (gs *AccessObject) getGameImpl(q postgresql.Queryer, field, value string) (*GameModel, error) {
+// queryer observedDB или observedTx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *observedRow
+}
+
+func (gs *AccessObject) getGameImpl(ctx context.Context, q queryer, field, value string) (*GameModel, error) {
 	g := &GameModel{}
 
 	//nolint: gosec уверены в том, что field корректно, так как сами его передаём
-	row := q.QueryRow(`SELECT g.id, g.slug, g.title, g.description,
+	row := q.QueryRowContext(ctx, `SELECT g.id, g.slug, g.title, g.description,
 						g.rules, g.code_example, g.bot_code, g.logo_uuid, g.background_uuid
 						FROM games g WHERE `+field+` = $1;`, value)
 	if err := row.Scan(&g.ID, &g.Slug, &g.Title, &g.Description,
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/HotCodeGroup/warscript-utils/models"
 	"github.com/HotCodeGroup/warscript-utils/testutils"
@@ -25,10 +27,9 @@ func TestGetGameBySlugOK(t *testing.T) {
 			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
 			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
 
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	game, err := Games.GetGameBySlug("pong")
+	game, err := gs.GetGameBySlug(context.Background(), "pong")
 	if err != nil {
 		t.Errorf("TestGetGameBySlugOK got unexpected error: %v", err)
 	}
@@ -64,10 +65,9 @@ func getGetGameBySlugErrors(t *testing.T, queryError, expectedError error) {
 
 	mock.ExpectQuery("SELECT").WithArgs("pong").WillReturnError(queryError)
 
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	if _, err = Games.GetGameBySlug("pong"); err != nil {
+	if _, err = gs.GetGameBySlug(context.Background(), "pong"); err != nil {
 		if errors.Cause(err) != expectedError {
 			t.Errorf("TestGetGameBySlugNotExists got unexpected error: %v", err)
 		}
@@ -102,10 +102,9 @@ func TestGetGameTotalPlayersBySlugOK(t *testing.T) {
 			AddRow(1))
 	mock.ExpectCommit()
 
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	total, err := Games.GetGameTotalPlayersBySlug("pong")
+	total, err := gs.GetGameTotalPlayersBySlug(context.Background(), "pong")
 	if err != nil {
 		t.Errorf("TestGetGameTotalPlayersBySlugOK got unexpected error: %v", err)
 	}
@@ -122,10 +121,9 @@ func TestGetGameTotalPlayersBySlugOK(t *testing.T) {
 
 func getGameTotalPlayersBySlugError(t *testing.T, db *sql.DB,
 	mock sqlmock.Sqlmock, expectedError error) {
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	_, err := Games.GetGameTotalPlayersBySlug("pong")
+	_, err := gs.GetGameTotalPlayersBySlug(context.Background(), "pong")
 	if errors.Cause(err) != expectedError {
 		t.Errorf("getGameTotalPlayersBySlugError got unexpected error: %v, expected: %v", err, expectedError)
 	}
@@ -224,9 +222,8 @@ func TestGetGameLeaderboardBySlugOK(t *testing.T) {
 			AddRow(1, 200).
 			AddRow(2, 500))
 
-	pqConn = db
-	Games = &AccessObject{}
-	authGPRC = &testutils.FakeAuthClient{
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
 		Users: map[int64]*models.InfoUser{
 			1: {
 				ID:       1,
@@ -240,7 +237,7 @@ func TestGetGameLeaderboardBySlugOK(t *testing.T) {
 				Active:    true,
 			},
 		},
-	}
+	}}
 
 	expected := []*ScoredUserModel{
 		{
@@ -258,7 +255,7 @@ func TestGetGameLeaderboardBySlugOK(t *testing.T) {
 		},
 	}
 
-	scored, err := Games.GetGameLeaderboardBySlug("pong", 6, 0)
+	scored, err := gs.GetGameLeaderboardBySlug(context.Background(), "pong", 6, 0)
 	if err != nil {
 		t.Errorf("GetGameLeaderboardBySlug got unexpected error: %v", err)
 	}
@@ -275,10 +272,9 @@ func TestGetGameLeaderboardBySlugOK(t *testing.T) {
 
 func getGameLeaderboardBySlugError(t *testing.T, db *sql.DB,
 	mock sqlmock.Sqlmock, expectedError error) {
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	_, err := Games.GetGameLeaderboardBySlug("pong", 6, 0)
+	_, err := gs.GetGameLeaderboardBySlug(context.Background(), "pong", 6, 0)
 	if errors.Cause(err) != expectedError {
 		t.Errorf("getGameLeaderboardBySlugError got unexpected error: %v, expected: %v", err, expectedError)
 	}
@@ -300,12 +296,11 @@ func TestGetGameLeaderboardBySlugAuthError(t *testing.T) {
 			AddRow(1, 200).
 			AddRow(2, 500))
 
-	pqConn = db
-	Games = &AccessObject{}
-	authGPRC = &testutils.FakeAuthClient{}
-	authGPRC.(*testutils.FakeAuthClient).SetNextFail(utils.ErrInternal)
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{}
+	gs.auth.(*fakeAuthClient).SetNextFail(utils.ErrInternal)
 
-	_, err = Games.GetGameLeaderboardBySlug("pong", 6, 0)
+	_, err = gs.GetGameLeaderboardBySlug(context.Background(), "pong", 6, 0)
 	if errors.Cause(err) != utils.ErrInternal {
 		t.Errorf("GetGameLeaderboardBySlug got unexpected error: %v, expected: %v", err, utils.ErrInternal)
 	}
@@ -362,10 +357,9 @@ func TestGetGameListOK(t *testing.T) {
 			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
 			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
 
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	games, err := Games.GetGameList()
+	games, err := gs.GetGameList(context.Background())
 	if err != nil {
 		t.Errorf("TestGetGameListOK got unexpected error: %v", err)
 	}
@@ -396,10 +390,9 @@ func TestGetGameListOK(t *testing.T) {
 
 func getGameListError(t *testing.T, db *sql.DB,
 	mock sqlmock.Sqlmock, expectedError error) {
-	pqConn = db
-	Games = &AccessObject{}
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
 
-	_, err := Games.GetGameList()
+	_, err := gs.GetGameList(context.Background())
 	if errors.Cause(err) != expectedError {
 		t.Errorf("getGameLeaderboardBySlugError got unexpected error: %v, expected: %v", err, expectedError)
 	}
@@ -420,6 +413,25 @@ func TestGetGameListQueryError(t *testing.T) {
 	getGameListError(t, db, mock, utils.ErrInternal)
 }
 
+func TestGetGameListTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if _, err = gs.GetGameList(ctx); errors.Cause(err) != errTimeout {
+		t.Errorf("TestGetGameListTimeout got unexpected error: %v, expected: %v", err, errTimeout)
+	}
+}
+
 func TestGetGameListScanError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -433,3 +445,1038 @@ func TestGetGameListScanError(t *testing.T) {
 			AddRow("kek", 2, 3, 4, "do not cheat", "a=5", "a=5", "kek", "lol"))
 	getGameListError(t, db, mock, utils.ErrInternal)
 }
+
+func TestGetGameScoreStatsBySlugOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("percentile_cont").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min", "max", "p50", "p90", "p99"}).
+			AddRow(3, 0, 9, 5.0, 8.2, 8.92))
+	mock.ExpectQuery("width_bucket").WithArgs(1, 0.0, 10.0, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"bucket", "count"}).
+			AddRow(1, 1).
+			AddRow(2, 2))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	stats, err := gs.GetGameScoreStatsBySlug(context.Background(), "pong", 2)
+	if err != nil {
+		t.Errorf("TestGetGameScoreStatsBySlugOK got unexpected error: %v", err)
+	}
+
+	expected := &ScoreStatsModel{
+		Count: 3,
+		Min:   0,
+		Max:   9,
+		P50:   5.0,
+		P90:   8.2,
+		P99:   8.92,
+		Buckets: []*ScoreBucketModel{
+			{From: 0, To: 5, Count: 1},
+			{From: 5, To: 10, Count: 2},
+		},
+	}
+
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("TestGetGameScoreStatsBySlugOK got unexpected result: %v; expected: %v",
+			stats, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameScoreStatsBySlugOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameScoreStatsBySlugEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("percentile_cont").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count", "min", "max", "p50", "p90", "p99"}).
+			AddRow(0, 0, 0, 0, 0, 0))
+	mock.ExpectRollback()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	stats, err := gs.GetGameScoreStatsBySlug(context.Background(), "pong", 2)
+	if err != nil {
+		t.Errorf("TestGetGameScoreStatsBySlugEmpty got unexpected error: %v", err)
+	}
+
+	if stats.Count != 0 || len(stats.Buckets) != 0 {
+		t.Errorf("TestGetGameScoreStatsBySlugEmpty got unexpected result: %v", stats)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameScoreStatsBySlugEmpty there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameScoreStatsBySlugNotExists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	_, err = gs.GetGameScoreStatsBySlug(context.Background(), "pong", 2)
+	if errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestGetGameScoreStatsBySlugNotExists got unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameScoreStatsBySlugNotExists there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGlobalLeaderboardOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("sum\\(r.norm_rank\\)").WithArgs(0, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "rating", "place", "games_played"}).
+			AddRow(2, 1.5, 1, 2).
+			AddRow(1, 0.5, 2, 1))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
+		Users: map[int64]*models.InfoUser{
+			1: {
+				ID:       1,
+				Username: "kek",
+				Active:   true,
+			},
+			2: {
+				ID:        2,
+				Username:  "kek1",
+				PhotoUUID: "ea04741c-68d4-4e90-814d-44ffedf7c685",
+			},
+		},
+	}}
+
+	expected := []*GlobalScoredUserModel{
+		{
+			ID:          2,
+			Username:    "kek1",
+			PhotoUUID:   sql.NullString{String: "ea04741c-68d4-4e90-814d-44ffedf7c685", Valid: true},
+			Rating:      1.5,
+			Rank:        1,
+			GamesPlayed: 2,
+		},
+		{
+			ID:          1,
+			Username:    "kek",
+			Active:      true,
+			Rating:      0.5,
+			Rank:        2,
+			GamesPlayed: 1,
+		},
+	}
+
+	leaders, err := gs.GetGlobalLeaderboard(context.Background(), FormulaRanks, 2, 0)
+	if err != nil {
+		t.Errorf("TestGetGlobalLeaderboardOK got unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(leaders, expected) {
+		t.Errorf("TestGetGlobalLeaderboardOK got unexpected result: %v; expected: %v",
+			leaders, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGlobalLeaderboardOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGlobalLeaderboardErrors(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WillReturnError(sql.ErrConnDone)
+	mock.ExpectQuery("SELECT").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "rating", "place", "games_played"}))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if _, err = gs.GetGlobalLeaderboard(context.Background(), "elo", 2, 0); errors.Cause(err) != utils.ErrInvalid {
+		t.Errorf("TestGetGlobalLeaderboardErrors got unexpected error: %v, expected: %v", err, utils.ErrInvalid)
+	}
+
+	if _, err = gs.GetGlobalLeaderboard(context.Background(), FormulaScores, 2, 0); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestGetGlobalLeaderboardErrors got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if _, err = gs.GetGlobalLeaderboard(context.Background(), FormulaScores, 2, 0); errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestGetGlobalLeaderboardErrors got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGlobalLeaderboardErrors there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUserGamesOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	lastPlayed := time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("cume_dist").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "title", "background_uuid",
+			"score", "place", "percentile", "last_played"}).
+			AddRow("pong", "Pong", "lol", 200, 3, 50.0, lastPlayed))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	userGames, err := gs.GetUserGames(context.Background(), 1)
+	if err != nil {
+		t.Errorf("TestGetUserGamesOK got unexpected error: %v", err)
+	}
+
+	expected := []*UserGameModel{
+		{
+			Slug:           "pong",
+			Title:          "Pong",
+			BackgroundUUID: sql.NullString{String: "lol", Valid: true},
+			Score:          200,
+			Rank:           3,
+			Percentile:     50,
+			LastPlayed:     lastPlayed,
+		},
+	}
+
+	if !reflect.DeepEqual(userGames, expected) {
+		t.Errorf("TestGetUserGamesOK got unexpected result: %v; expected: %v",
+			userGames, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetUserGamesOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUserGamesInternal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs(1).WillReturnError(sql.ErrConnDone)
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if _, err = gs.GetUserGames(context.Background(), 1); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestGetUserGamesInternal got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetUserGamesInternal there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameLeaderboardForUsersOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("ANY").WithArgs(1, "{1,2}", 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "score", "place", "global_place"}).
+			AddRow(2, 500, 1, 3).
+			AddRow(1, 200, 2, 10))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
+		Users: map[int64]*models.InfoUser{
+			1: {ID: 1, Username: "kek", Active: true},
+			2: {ID: 2, Username: "kek1", Active: true},
+		},
+	}}
+
+	expected := []*RankedUserModel{
+		{
+			ScoredUserModel: ScoredUserModel{ID: 2, Username: "kek1", Active: true, Score: 500},
+			Rank:            1,
+			GlobalRank:      3,
+		},
+		{
+			ScoredUserModel: ScoredUserModel{ID: 1, Username: "kek", Active: true, Score: 200},
+			Rank:            2,
+			GlobalRank:      10,
+		},
+	}
+
+	ranked, err := gs.GetGameLeaderboardForUsers(context.Background(), "pong", []int64{1, 2}, 5, 0)
+	if err != nil {
+		t.Errorf("TestGetGameLeaderboardForUsersOK got unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(ranked, expected) {
+		t.Errorf("TestGetGameLeaderboardForUsersOK got unexpected result: %v; expected: %v",
+			ranked, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameLeaderboardForUsersOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameLeaderboardForUsersEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("ANY").WithArgs(1, "{1}", 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "score", "place", "global_place"}))
+	mock.ExpectRollback()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	_, err = gs.GetGameLeaderboardForUsers(context.Background(), "pong", []int64{1}, 5, 0)
+	if errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestGetGameLeaderboardForUsersEmpty got unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameLeaderboardForUsersEmpty there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestFollows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO follows").WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT followee_id").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"followee_id"}).AddRow(2))
+	mock.ExpectExec("DELETE FROM follows").WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM follows").WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if err = gs.FollowUser(context.Background(), 1, 1); err == nil {
+		t.Errorf("TestFollows self follow must fail")
+	}
+
+	if err = gs.FollowUser(context.Background(), 1, 2); err != nil {
+		t.Errorf("TestFollows got unexpected follow error: %v", err)
+	}
+
+	followed, err := gs.GetFollowedUsers(context.Background(), 1)
+	if err != nil {
+		t.Errorf("TestFollows got unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(followed, []int64{2}) {
+		t.Errorf("TestFollows got unexpected followed: %v", followed)
+	}
+
+	if err = gs.UnfollowUser(context.Background(), 1, 2); err != nil {
+		t.Errorf("TestFollows got unexpected unfollow error: %v", err)
+	}
+
+	if err = gs.UnfollowUser(context.Background(), 1, 2); errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestFollows got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestFollows there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameWindowLeaderboardBySlugOpen(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	w, _ := NewLeaderboardWindow(WindowDay, time.Now(), time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("FROM score_events").WithArgs(1, w.Start, w.End, 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "points", "place"}).
+			AddRow(1, 30, 1))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
+		Users: map[int64]*models.InfoUser{
+			1: {ID: 1, Username: "kek", Active: true},
+		},
+	}}
+
+	leaders, err := gs.GetGameWindowLeaderboardBySlug(context.Background(), "pong", w, 5, 0)
+	if err != nil {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugOpen got unexpected error: %v", err)
+	}
+
+	expected := []*WindowScoredUserModel{
+		{ID: 1, Username: "kek", Active: true, Points: 30, Rank: 1},
+	}
+	if !reflect.DeepEqual(leaders, expected) {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugOpen got unexpected result: %v; expected: %v",
+			leaders, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugOpen there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameWindowLeaderboardBySlugClosed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	w, _ := NewLeaderboardWindow(WindowWeek, time.Date(2019, 5, 22, 0, 0, 0, 0, time.UTC), time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("FROM leaderboard_snapshot_windows").WithArgs(1, WindowWeek, w.Start).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	// скрытых отбрасываем при чтении, а не при сохранении снапшота
+	mock.ExpectQuery("FROM leaderboard_snapshots (?s:.)*AND hidden").WithArgs(1, WindowWeek, w.Start, 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "points", "place"}).
+			AddRow(1, 30, 1))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
+		Users: map[int64]*models.InfoUser{
+			1: {ID: 1, Username: "kek", Active: true},
+		},
+	}}
+
+	if _, err = gs.GetGameWindowLeaderboardBySlug(context.Background(), "pong", w, 5, 0); err != nil {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugClosed got unexpected error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugClosed there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetGameWindowLeaderboardBySlugNotSnapshotted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	w, _ := NewLeaderboardWindow(WindowMonth, time.Date(1990, 5, 22, 0, 0, 0, 0, time.UTC), time.UTC)
+
+	// окна ещё нет в leaderboard_snapshots: считаем на лету и ничего не пишем
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("FROM leaderboard_snapshot_windows").WithArgs(1, WindowMonth, w.Start).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectQuery("FROM score_events").WithArgs(1, w.Start, w.End, 0, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "points", "place"}))
+	mock.ExpectRollback()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	_, err = gs.GetGameWindowLeaderboardBySlug(context.Background(), "pong", w, 5, 0)
+	if errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugNotSnapshotted got unexpected error: %v, expected: %v",
+			err, utils.ErrNotExists)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetGameWindowLeaderboardBySlugNotSnapshotted there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestWindowSnapshotHiddenPostgres(t *testing.T) {
+	db := openTestPostgres(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	if _, err := gs.UpsertGame(ctx, &GameModel{Slug: "pong", Title: "Pong"}); err != nil {
+		t.Fatalf("TestWindowSnapshotHiddenPostgres can not create game: %v", err)
+	}
+	for _, userID := range []int64{7, 8} {
+		if _, _, err := gs.UpdateUserScore(ctx, "pong", userID, &ScoreChange{Delta: 1, Source: "test"}); err != nil {
+			t.Fatalf("TestWindowSnapshotHiddenPostgres can not create player %d: %v", userID, err)
+		}
+	}
+
+	// история только дописывается, поэтому очки за прошлое окно кладём в неё напрямую
+	w, _ := NewLeaderboardWindow(WindowDay, time.Now().AddDate(0, 0, -2), time.UTC)
+	_, err := db.Exec(`INSERT INTO score_events (user_id, game_id, old_score, new_score, created_at)
+		SELECT user_id, game_id, 0, 10 * user_id, $1::timestamptz FROM users_games;`, w.Start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("TestWindowSnapshotHiddenPostgres can not write score events: %v", err)
+	}
+
+	// окно сохраняется, пока игрок 8 скрыт, и скрытие потом снимают
+	if err = gs.SetScoreHidden(ctx, "pong", 8, true); err != nil {
+		t.Fatalf("TestWindowSnapshotHiddenPostgres can not hide player: %v", err)
+	}
+	if err = gs.SnapshotWindow(ctx, w); err != nil {
+		t.Fatalf("TestWindowSnapshotHiddenPostgres can not snapshot window: %v", err)
+	}
+
+	cases := []struct {
+		hidden   int64
+		expected [][2]int64
+	}{
+		{hidden: 8, expected: [][2]int64{{7, 1}}},
+		{expected: [][2]int64{{8, 1}, {7, 2}}},
+		{hidden: 7, expected: [][2]int64{{8, 1}}},
+	}
+
+	for i, c := range cases {
+		for _, userID := range []int64{7, 8} {
+			if err = gs.SetScoreHidden(ctx, "pong", userID, userID == c.hidden); err != nil {
+				t.Fatalf("[%d] TestWindowSnapshotHiddenPostgres can not set hidden: %v", i, err)
+			}
+		}
+
+		leaders, err := gs.GetGameWindowLeaderboardBySlug(ctx, "pong", w, 10, 0)
+		if err != nil {
+			t.Fatalf("[%d] TestWindowSnapshotHiddenPostgres got unexpected error: %v", i, err)
+		}
+		got := make([][2]int64, len(leaders))
+		for j, l := range leaders {
+			got[j] = [2]int64{l.ID, l.Rank}
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("[%d] TestWindowSnapshotHiddenPostgres got %v, expected %v", i, got, c.expected)
+		}
+	}
+}
+
+func TestSnapshotWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	w, _ := NewLeaderboardWindow(WindowDay, time.Date(2019, 5, 22, 0, 0, 0, 0, time.UTC), time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM games").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	// первая игра уже сохранена
+	mock.ExpectExec("INSERT INTO leaderboard_snapshot_windows").WithArgs(1, WindowDay, w.Start).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO leaderboard_snapshot_windows").WithArgs(2, WindowDay, w.Start).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO leaderboard_snapshots").WithArgs(2, WindowDay, w.Start, w.End).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if err = gs.SnapshotWindow(context.Background(), w); err != nil {
+		t.Errorf("TestSnapshotWindow got unexpected error: %v", err)
+	}
+
+	open, _ := NewLeaderboardWindow(WindowDay, time.Now(), time.UTC)
+	if err = gs.SnapshotWindow(context.Background(), open); errors.Cause(err) != utils.ErrInvalid {
+		t.Errorf("TestSnapshotWindow got unexpected error: %v, expected: %v", err, utils.ErrInvalid)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestSnapshotWindow there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUserScoreEventsOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	createdAt := time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("FROM score_events").WithArgs(1, 7, 0, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "old_score", "new_score",
+			"reason", "source", "match_id", "created_at"}).
+			AddRow(3, 7, 10, 25, "match won", "warscript-bots", "42", createdAt).
+			AddRow(1, 7, 0, 10, "", "unknown", nil, createdAt))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	events, err := gs.GetUserScoreEvents(context.Background(), "pong", 7, 10, 0)
+	if err != nil {
+		t.Errorf("TestGetUserScoreEventsOK got unexpected error: %v", err)
+	}
+
+	expected := []*ScoreEventModel{
+		{
+			ID:        3,
+			UserID:    7,
+			OldScore:  10,
+			NewScore:  25,
+			Reason:    "match won",
+			Source:    "warscript-bots",
+			MatchID:   sql.NullString{String: "42", Valid: true},
+			CreatedAt: createdAt,
+		},
+		{
+			ID:        1,
+			UserID:    7,
+			OldScore:  0,
+			NewScore:  10,
+			Source:    "unknown",
+			CreatedAt: createdAt,
+		},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("TestGetUserScoreEventsOK got unexpected result: %v; expected: %v", events, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetUserScoreEventsOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateUserScoreOK(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectExec("set_config").WithArgs("match won", "warscript-bots", "42").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// старые очки -- из новых, без чтения users_games в той же команде
+	mock.ExpectQuery(`INSERT INTO users_games (?s:.)*RETURNING score - \$3, score;`).WithArgs(7, 1, 15).
+		WillReturnRows(sqlmock.NewRows([]string{"old_score", "score"}).AddRow(10, 25))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	oldScore, newScore, err := gs.UpdateUserScore(context.Background(), "pong", 7, &ScoreChange{
+		Delta:   15,
+		Reason:  "match won",
+		Source:  "warscript-bots",
+		MatchID: "42",
+	})
+	if err != nil {
+		t.Errorf("TestUpdateUserScoreOK got unexpected error: %v", err)
+	}
+
+	if oldScore != 10 || newScore != 25 {
+		t.Errorf("TestUpdateUserScoreOK got unexpected result: %d -> %d", oldScore, newScore)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestUpdateUserScoreOK there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpdateUserScorePostgres(t *testing.T) {
+	db := openTestPostgres(t)
+	defer db.Close()
+
+	ctx := context.Background()
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	if _, err := gs.UpsertGame(ctx, &GameModel{Slug: "pong", Title: "Pong"}); err != nil {
+		t.Fatalf("TestUpdateUserScorePostgres can not create game: %v", err)
+	}
+
+	cases := []struct {
+		delta    int32
+		expected [2]int32
+	}{
+		{delta: 10, expected: [2]int32{0, 10}},
+		{delta: 15, expected: [2]int32{10, 25}},
+		{delta: -5, expected: [2]int32{25, 20}},
+	}
+
+	for i, c := range cases {
+		oldScore, newScore, err := gs.UpdateUserScore(ctx, "pong", 7, &ScoreChange{Delta: c.delta, Source: "test"})
+		if err != nil {
+			t.Fatalf("[%d] TestUpdateUserScorePostgres got unexpected error: %v", i, err)
+		}
+		if got := [2]int32{oldScore, newScore}; got != c.expected {
+			t.Errorf("[%d] TestUpdateUserScorePostgres got %d -> %d, expected %d -> %d",
+				i, oldScore, newScore, c.expected[0], c.expected[1])
+		}
+	}
+
+	events, err := gs.GetUserScoreEvents(ctx, "pong", 7, 10, 0)
+	if err != nil {
+		t.Fatalf("TestUpdateUserScorePostgres got unexpected error: %v", err)
+	}
+	if len(events) != len(cases) || events[0].OldScore != 25 || events[0].NewScore != 20 {
+		t.Errorf("TestUpdateUserScorePostgres got unexpected events: %+v", events)
+	}
+}
+
+func TestUpdateUserScoreInternal(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectExec("set_config").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("INSERT INTO users_games").WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	_, _, err = gs.UpdateUserScore(context.Background(), "pong", 7, &ScoreChange{Delta: 15, Source: "test"})
+	if errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestUpdateUserScoreInternal got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestUpdateUserScoreInternal there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExportGameLeaderboardBatches(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(1, "pong", "Pong", "very cool", "do not cheat", "a=5", "a=5", "kek", "lol"))
+	mock.ExpectQuery("rank\\(\\)").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "score", "place"}).
+			AddRow(2, 500, 1).
+			AddRow(1, 200, 2).
+			AddRow(3, 200, 2))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	gs.auth = &fakeAuthClient{testutils.FakeAuthClient{
+		Users: map[int64]*models.InfoUser{
+			1: {ID: 1, Username: "kek"},
+			2: {ID: 2, Username: "kek1"},
+			3: {ID: 3, Username: "kek2"},
+		},
+	}}
+
+	batches := make([][]string, 0)
+	err = gs.ExportGameLeaderboard(context.Background(), "pong", 2, func(batch []*RankedUserModel) error {
+		names := make([]string, len(batch))
+		for i, u := range batch {
+			names[i] = u.Username
+		}
+		batches = append(batches, names)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("TestExportGameLeaderboardBatches got unexpected error: %v", err)
+	}
+
+	expected := [][]string{{"kek1", "kek"}, {"kek2"}}
+	if !reflect.DeepEqual(batches, expected) {
+		t.Errorf("TestExportGameLeaderboardBatches got unexpected batches: %v; expected: %v", batches, expected)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestExportGameLeaderboardBatches there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestUpsertGame(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	g := &GameModel{
+		Slug:  "pong",
+		Title: "Pong",
+	}
+
+	mock.ExpectQuery("INSERT INTO games").
+		WithArgs("pong", "Pong", "", "", "", "", sql.NullString{}, sql.NullString{}).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	// второй раз ничего не поменялось
+	mock.ExpectQuery("INSERT INTO games").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("INSERT INTO games").
+		WillReturnError(errors.New("duplicate title"))
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	changed, err := gs.UpsertGame(context.Background(), g)
+	if err != nil || !changed || g.ID != 7 {
+		t.Errorf("TestUpsertGame got unexpected result: %v, %v, id %d", changed, err, g.ID)
+	}
+
+	changed, err = gs.UpsertGame(context.Background(), g)
+	if err != nil || changed {
+		t.Errorf("TestUpsertGame got unexpected result on unchanged game: %v, %v", changed, err)
+	}
+
+	if _, err = gs.UpsertGame(context.Background(), g); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestUpsertGame got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestUpsertGame there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRecomputeWindow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	w, _ := NewLeaderboardWindow(WindowMonth, time.Date(2019, 4, 10, 0, 0, 0, 0, time.UTC), time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM leaderboard_snapshot_windows").WithArgs(WindowMonth, w.Start).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id FROM games").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectExec("INSERT INTO leaderboard_snapshot_windows").WithArgs(1, WindowMonth, w.Start).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO leaderboard_snapshots").WithArgs(1, WindowMonth, w.Start, w.End).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if err = gs.RecomputeWindow(context.Background(), w); err != nil {
+		t.Errorf("TestRecomputeWindow got unexpected error: %v", err)
+	}
+
+	open, _ := NewLeaderboardWindow(WindowMonth, time.Now(), time.UTC)
+	if err = gs.RecomputeWindow(context.Background(), open); errors.Cause(err) != utils.ErrInvalid {
+		t.Errorf("TestRecomputeWindow got unexpected error: %v, expected: %v", err, utils.ErrInvalid)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestRecomputeWindow there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetUserGrants(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM grants").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "slug", "role", "granted_by", "created_at"}).
+			AddRow(1, 7, "", "moderator", 1, created).
+			AddRow(2, 7, "pong", "author", 1, created))
+	mock.ExpectQuery("FROM grants").WithArgs(8).WillReturnError(sql.ErrConnDone)
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	grants, err := gs.GetUserGrants(context.Background(), 7)
+	if err != nil {
+		t.Errorf("TestGetUserGrants got unexpected error: %v", err)
+	}
+	expected := []*GrantModel{
+		{ID: 1, UserID: 7, Role: roleModerator, GrantedBy: 1, CreatedAt: created},
+		{ID: 2, UserID: 7, GameSlug: "pong", Role: roleAuthor, GrantedBy: 1, CreatedAt: created},
+	}
+	if !reflect.DeepEqual(grants, expected) {
+		t.Errorf("TestGetUserGrants got %+v, expected %+v", grants, expected)
+	}
+
+	if _, err = gs.GetUserGrants(context.Background(), 8); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestGetUserGrants got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestGetUserGrants there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAddGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	created := time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	// на одну игру
+	mock.ExpectQuery("FROM games").WithArgs("pong").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "slug", "title", "description", "rules",
+			"code_example", "bot_code", "logo_uuid", "background_uuid"}).
+			AddRow(3, "pong", "Pong", "", "", "", "", nil, nil))
+	mock.ExpectQuery("INSERT INTO grants").WithArgs(7, sql.NullInt64{Int64: 3, Valid: true}, "author", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, created))
+	// на все игры, уже выдана
+	mock.ExpectQuery("INSERT INTO grants").WithArgs(7, sql.NullInt64{}, "moderator", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	// нет игры
+	mock.ExpectQuery("FROM games").WithArgs("ping").WillReturnError(sql.ErrNoRows)
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	g := &GrantModel{UserID: 7, GameSlug: "pong", Role: roleAuthor, GrantedBy: 1}
+	added, err := gs.AddGrant(context.Background(), g)
+	if err != nil || !added || g.ID != 5 || !g.CreatedAt.Equal(created) {
+		t.Errorf("TestAddGrant got unexpected result: %v, %v, %+v", added, err, g)
+	}
+
+	added, err = gs.AddGrant(context.Background(), &GrantModel{UserID: 7, Role: roleModerator, GrantedBy: 1})
+	if err != nil || added {
+		t.Errorf("TestAddGrant got unexpected result on existing grant: %v, %v", added, err)
+	}
+
+	_, err = gs.AddGrant(context.Background(), &GrantModel{UserID: 7, GameSlug: "ping", Role: roleAuthor, GrantedBy: 1})
+	if errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestAddGrant got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestAddGrant there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDeleteGrant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("game_id IS NULL").WithArgs(7, "moderator").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("slug = \\$3").WithArgs(7, "author", "pong").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM grants").WillReturnError(sql.ErrConnDone)
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if err = gs.DeleteGrant(context.Background(), 7, "", roleModerator); err != nil {
+		t.Errorf("TestDeleteGrant got unexpected error: %v", err)
+	}
+	if err = gs.DeleteGrant(context.Background(), 7, "pong", roleAuthor); errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestDeleteGrant got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+	if err = gs.DeleteGrant(context.Background(), 7, "", roleAdmin); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestDeleteGrant got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestDeleteGrant there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSetScoreHidden(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users_games SET hidden").WithArgs("pong", 7, true).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE users_games SET hidden").WithArgs("pong", 8, false).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE users_games SET hidden").WillReturnError(sql.ErrConnDone)
+
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	if err = gs.SetScoreHidden(context.Background(), "pong", 7, true); err != nil {
+		t.Errorf("TestSetScoreHidden got unexpected error: %v", err)
+	}
+	if err = gs.SetScoreHidden(context.Background(), "pong", 8, false); errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("TestSetScoreHidden got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+	if err = gs.SetScoreHidden(context.Background(), "pong", 7, false); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestSetScoreHidden got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestSetScoreHidden there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Файлы в папке игры games/<slug>/. Длинный текст лежит в markdown и js,
+// чтобы его было удобно ревьюить в git, а в game.yaml только короткие поля
+const (
+	gameMetaFile        = "game.yaml"
+	gameDescriptionFile = "description.md"
+	gameRulesFile       = "rules.md"
+	gameCodeExampleFile = "code_example.js"
+	gameBotCodeFile     = "bot_code.js"
+)
+
+var (
+	// то же ограничение, что и games_slug_check в migrationGamesUp
+	gameSlugRe = regexp.MustCompile(`^[\w-]+$`)
+	uuidRe     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// gameMeta поля game.yaml
+type gameMeta struct {
+	// Slug необязателен, но если указан, должен совпадать с именем папки
+	Slug           string `yaml:"slug"`
+	Title          string `yaml:"title"`
+	LogoUUID       string `yaml:"logo_uuid"`
+	BackgroundUUID string `yaml:"background_uuid"`
+}
+
+// parseGameMeta разбирает game.yaml. Неизвестные и повторяющиеся ключи --
+// ошибка, чтобы опечатка в имени поля не терялась молча
+func parseGameMeta(data []byte) (*gameMeta, error) {
+	meta := &gameMeta{}
+	if err := yaml.UnmarshalStrict(data, meta); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// loadGameDefinition читает и проверяет папку одной игры
+func loadGameDefinition(dir string) (*GameModel, error) {
+	slug := filepath.Base(dir)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, gameMetaFile))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: can not read %s", slug, gameMetaFile)
+	}
+
+	meta, err := parseGameMeta(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s: invalid %s", slug, gameMetaFile)
+	}
+
+	texts := make(map[string]string)
+	for _, name := range []string{gameDescriptionFile, gameRulesFile, gameCodeExampleFile, gameBotCodeFile} {
+		text, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s: can not read %s", slug, name)
+		}
+		texts[name] = string(text)
+	}
+
+	g := &GameModel{
+		Slug:           slug,
+		Title:          meta.Title,
+		Description:    texts[gameDescriptionFile],
+		Rules:          texts[gameRulesFile],
+		CodeExample:    texts[gameCodeExampleFile],
+		BotCode:        texts[gameBotCodeFile],
+		LogoUUID:       photoUUIDToNull(strings.ToLower(meta.LogoUUID)),
+		BackgroundUUID: photoUUIDToNull(strings.ToLower(meta.BackgroundUUID)),
+	}
+
+	if meta.Slug != "" && meta.Slug != slug {
+		return nil, errors.Errorf("%s: slug %q in %s does not match folder name", slug, meta.Slug, gameMetaFile)
+	}
+	if err = validateGameDefinition(g); err != nil {
+		return nil, errors.Wrap(err, slug)
+	}
+
+	return g, nil
+}
+
+// validateGameDefinition проверяет то, что иначе упадёт на ограничениях таблицы games
+func validateGameDefinition(g *GameModel) error {
+	problems := make([]string, 0)
+	if !gameSlugRe.MatchString(g.Slug) {
+		problems = append(problems, "slug may contain only letters, digits, '-' and '_'")
+	}
+	if strings.TrimSpace(g.Title) == "" {
+		problems = append(problems, "title is required")
+	}
+	if strings.TrimSpace(g.Rules) == "" {
+		problems = append(problems, gameRulesFile+" is empty")
+	}
+	if strings.TrimSpace(g.CodeExample) == "" {
+		problems = append(problems, gameCodeExampleFile+" is empty")
+	}
+	if strings.TrimSpace(g.BotCode) == "" {
+		problems = append(problems, gameBotCodeFile+" is empty")
+	}
+	if !uuidRe.MatchString(g.LogoUUID.String) {
+		problems = append(problems, "logo_uuid must be a uuid")
+	}
+	if !uuidRe.MatchString(g.BackgroundUUID.String) {
+		problems = append(problems, "background_uuid must be a uuid")
+	}
+
+	if len(problems) != 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// loadGameDefinitions читает все папки игр из dir. Ошибки всех игр
+// собираются вместе, чтобы в ревью было видно всё сразу
+func loadGameDefinitions(dir string) ([]*GameModel, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read game definitions")
+	}
+
+	games := make([]*GameModel, 0, len(entries))
+	problems := make([]string, 0)
+	titles := make(map[string]string)
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		g, err := loadGameDefinition(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+
+		// slug и title в базе CITEXT UNIQUE
+		title := strings.ToLower(g.Title)
+		if other, ok := titles[title]; ok {
+			problems = append(problems, fmt.Sprintf("%s: title %q is already used by %s", g.Slug, g.Title, other))
+			continue
+		}
+		titles[title] = g.Slug
+
+		games = append(games, g)
+	}
+
+	if len(problems) != 0 {
+		return nil, errors.Errorf("invalid game definitions:\n%s", strings.Join(problems, "\n"))
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].Slug < games[j].Slug
+	})
+
+	return games, nil
+}
+
+const (
+	// GameDiffCreate игры ещё нет в базе
+	GameDiffCreate = "create"
+	// GameDiffUpdate игра есть, но поля отличаются
+	GameDiffUpdate = "update"
+	// GameDiffUnchanged игра в базе совпадает с файлами
+	GameDiffUnchanged = "unchanged"
+	// GameDiffMissing игра есть только в базе; apply её не удаляет,
+	// потому что вместе с ней удалятся все очки игроков
+	GameDiffMissing = "missing"
+)
+
+// GameDiff различие одной игры между файлами и базой
+type GameDiff struct {
+	Slug   string
+	Action string
+	Fields []string
+	Game   *GameModel
+}
+
+func (d *GameDiff) String() string {
+	if len(d.Fields) == 0 {
+		return fmt.Sprintf("%-9s %s", d.Action, d.Slug)
+	}
+
+	return fmt.Sprintf("%-9s %s (%s)", d.Action, d.Slug, strings.Join(d.Fields, ", "))
+}
+
+// changedGameFields поля, в которых want отличается от have
+func changedGameFields(want, have *GameModel) []string {
+	fields := make([]string, 0)
+	if want.Title != have.Title {
+		fields = append(fields, "title")
+	}
+	if want.Description != have.Description {
+		fields = append(fields, "description")
+	}
+	if want.Rules != have.Rules {
+		fields = append(fields, "rules")
+	}
+	if want.CodeExample != have.CodeExample {
+		fields = append(fields, "code_example")
+	}
+	if want.BotCode != have.BotCode {
+		fields = append(fields, "bot_code")
+	}
+	if !strings.EqualFold(want.GetLogoUUID(), have.GetLogoUUID()) {
+		fields = append(fields, "logo_uuid")
+	}
+	if !strings.EqualFold(want.GetBackgroundUUID(), have.GetBackgroundUUID()) {
+		fields = append(fields, "background_uuid")
+	}
+
+	return fields
+}
+
+// diffGames сравнивает определения игр с тем, что лежит в базе
+func diffGames(defs, current []*GameModel) []*GameDiff {
+	bySlug := make(map[string]*GameModel, len(current))
+	for _, g := range current {
+		bySlug[strings.ToLower(g.Slug)] = g
+	}
+
+	diffs := make([]*GameDiff, 0, len(defs))
+	for _, def := range defs {
+		key := strings.ToLower(def.Slug)
+		have, ok := bySlug[key]
+		delete(bySlug, key)
+
+		d := &GameDiff{Slug: def.Slug, Game: def}
+		switch {
+		case !ok:
+			d.Action = GameDiffCreate
+		default:
+			d.Fields = changedGameFields(def, have)
+			d.Action = GameDiffUnchanged
+			if len(d.Fields) != 0 {
+				d.Action = GameDiffUpdate
+			}
+		}
+		diffs = append(diffs, d)
+	}
+
+	for _, g := range current {
+		if _, ok := bySlug[strings.ToLower(g.Slug)]; ok {
+			diffs = append(diffs, &GameDiff{Slug: g.Slug, Action: GameDiffMissing, Game: g})
+		}
+	}
+
+	return diffs
+}
+
+// planGameDefinitions читает определения из dir и сравнивает их с базой
+func planGameDefinitions(ctx context.Context, games GameAccessObject, dir string) ([]*GameDiff, error) {
+	defs, err := loadGameDefinitions(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := games.GetGameList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffGames(defs, current), nil
+}
+
+// applyGameDiffs сохраняет созданные и изменённые игры.
+// Повторный запуск с теми же файлами ничего не меняет
+func applyGameDiffs(ctx context.Context, games GameAccessObject, diffs []*GameDiff) error {
+	for _, d := range diffs {
+		if d.Action != GameDiffCreate && d.Action != GameDiffUpdate {
+			continue
+		}
+
+		if _, err := games.UpsertGame(ctx, d.Game); err != nil {
+			return errors.Wrapf(err, "can not apply %s", d.Slug)
+		}
+	}
+
+	return nil
+}
+
+// printGameDiffs печатает план изменений
+func printGameDiffs(diffs []*GameDiff) {
+	for _, d := range diffs {
+		fmt.Fprintln(os.Stdout, d.String())
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const pongMeta = `# Pong
+title: Pong
+logo_uuid: 2EB4A823-3A6D-4CBA-8767-4D4946890F4F # из аплоадера
+background_uuid: "2eb4a823-3a6d-4cba-8767-4d4946890f4e"
+`
+
+// writeGameDefinitions создаёт games/<slug>/<file> во временной директории
+func writeGameDefinitions(t *testing.T, games map[string]map[string]string) string {
+	dir, err := ioutil.TempDir("", "warscript-games-defs")
+	if err != nil {
+		t.Fatalf("can not create temp dir: %s", err)
+	}
+
+	for slug, files := range games {
+		if err = os.Mkdir(filepath.Join(dir, slug), 0700); err != nil {
+			t.Fatalf("can not create %s: %s", slug, err)
+		}
+		for name, content := range files {
+			if err = ioutil.WriteFile(filepath.Join(dir, slug, name), []byte(content), 0600); err != nil {
+				t.Fatalf("can not write %s/%s: %s", slug, name, err)
+			}
+		}
+	}
+
+	return dir
+}
+
+func pongFiles() map[string]string {
+	return map[string]string{
+		gameMetaFile:        pongMeta,
+		gameDescriptionFile: "Very cool game(net)\n",
+		gameRulesFile:       "Do not cheat, please\n",
+		gameCodeExampleFile: "const a = 5;\n",
+		gameBotCodeFile:     "const a = 5;\n",
+	}
+}
+
+func TestParseGameMeta(t *testing.T) {
+	meta, err := parseGameMeta([]byte("---\nslug: pong\ntitle: 'Bob''s Pong' # comment\n" +
+		"logo_uuid: >-\n  2eb4a823-3a6d-4cba-8767-4d4946890f4f\n"))
+	if err != nil {
+		t.Fatalf("TestParseGameMeta got unexpected error: %v", err)
+	}
+
+	expected := &gameMeta{Slug: "pong", Title: "Bob's Pong", LogoUUID: "2eb4a823-3a6d-4cba-8767-4d4946890f4f"}
+	if !reflect.DeepEqual(meta, expected) {
+		t.Errorf("TestParseGameMeta got %+v, expected %+v", meta, expected)
+	}
+
+	for i, bad := range []string{
+		"title:\n  nested: value",
+		"title: [Pong, Tanks]",
+		"score: 5",
+		"title: a\ntitle: b",
+		`title: "open`,
+		"just text",
+	} {
+		if _, err = parseGameMeta([]byte(bad)); err == nil {
+			t.Errorf("[%d] TestParseGameMeta expected error for %q", i, bad)
+		}
+	}
+}
+
+func TestLoadGameDefinitions(t *testing.T) {
+	dir := writeGameDefinitions(t, map[string]map[string]string{
+		"pong": pongFiles(),
+	})
+	defer os.RemoveAll(dir)
+
+	games, err := loadGameDefinitions(dir)
+	if err != nil {
+		t.Fatalf("TestLoadGameDefinitions got unexpected error: %v", err)
+	}
+
+	expected := []*GameModel{
+		{
+			Slug:           "pong",
+			Title:          "Pong",
+			Description:    "Very cool game(net)\n",
+			Rules:          "Do not cheat, please\n",
+			CodeExample:    "const a = 5;\n",
+			BotCode:        "const a = 5;\n",
+			LogoUUID:       sql.NullString{String: "2eb4a823-3a6d-4cba-8767-4d4946890f4f", Valid: true},
+			BackgroundUUID: sql.NullString{String: "2eb4a823-3a6d-4cba-8767-4d4946890f4e", Valid: true},
+		},
+	}
+	if !reflect.DeepEqual(games, expected) {
+		t.Errorf("TestLoadGameDefinitions got %+v, expected %+v", games[0], expected[0])
+	}
+}
+
+func TestLoadGameDefinitionsInvalid(t *testing.T) {
+	noRules := pongFiles()
+	delete(noRules, gameRulesFile)
+
+	badUUID := pongFiles()
+	badUUID[gameMetaFile] = "title: Tanks\nlogo_uuid: kek\nbackground_uuid: kek\n"
+
+	otherSlug := pongFiles()
+	otherSlug[gameMetaFile] = "slug: pong\n" + pongMeta
+
+	sameTitle := pongFiles()
+
+	dir := writeGameDefinitions(t, map[string]map[string]string{
+		"pong":      pongFiles(),
+		"no-rules":  noRules,
+		"tanks":     badUUID,
+		"snake":     otherSlug,
+		"pong-copy": sameTitle,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := loadGameDefinitions(dir)
+	if err == nil {
+		t.Fatal("TestLoadGameDefinitionsInvalid expected error")
+	}
+
+	for _, slug := range []string{"no-rules", "tanks", "snake", "pong-copy"} {
+		if !strings.Contains(err.Error(), slug+":") {
+			t.Errorf("TestLoadGameDefinitionsInvalid error does not mention %s: %v", slug, err)
+		}
+	}
+}
+
+func TestDiffAndApplyGames(t *testing.T) {
+	srv := initTests()
+
+	dir := writeGameDefinitions(t, map[string]map[string]string{
+		"pong": pongFiles(),
+		"tanks": {
+			gameMetaFile:        "title: Tanks\nlogo_uuid: 2eb4a823-3a6d-4cba-8767-4d4946890f4f\nbackground_uuid: 2eb4a823-3a6d-4cba-8767-4d4946890f4f\n",
+			gameDescriptionFile: "",
+			gameRulesFile:       "Shoot",
+			gameCodeExampleFile: "shoot();",
+			gameBotCodeFile:     "shoot();",
+		},
+	})
+	defer os.RemoveAll(dir)
+
+	diffs, err := planGameDefinitions(context.Background(), srv.games, dir)
+	if err != nil {
+		t.Fatalf("TestDiffAndApplyGames got unexpected error: %v", err)
+	}
+
+	got := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		got = append(got, d.String())
+	}
+	expected := []string{
+		"update    pong (description, rules, code_example, bot_code, background_uuid)",
+		"create    tanks",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("TestDiffAndApplyGames got diff %q, expected %q", got, expected)
+	}
+
+	if err = applyGameDiffs(context.Background(), srv.games, diffs); err != nil {
+		t.Fatalf("TestDiffAndApplyGames got unexpected apply error: %v", err)
+	}
+
+	// повторный запуск ничего не меняет
+	diffs, err = planGameDefinitions(context.Background(), srv.games, dir)
+	if err != nil {
+		t.Fatalf("TestDiffAndApplyGames got unexpected error: %v", err)
+	}
+	for _, d := range diffs {
+		if d.Action != GameDiffUnchanged {
+			t.Errorf("TestDiffAndApplyGames got %s after apply", d)
+		}
+	}
+}
+
+func TestDiffGamesMissing(t *testing.T) {
+	diffs := diffGames(nil, []*GameModel{{Slug: "pong"}})
+	if len(diffs) != 1 || diffs[0].Action != GameDiffMissing {
+		t.Errorf("TestDiffGamesMissing got unexpected diff: %+v", diffs)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// FormulaRanks сумма нормированных мест: за каждую игру игрок получает
+	// 1 - percent_rank(), то есть 1 за первое место и 0 за последнее
+	FormulaRanks = "ranks"
+	// FormulaScores сумма очков, взвешенных максимумом игры, чтобы игры
+	// с крупными очками не перевешивали остальные
+	FormulaScores = "scores"
+)
+
+// globalFormulas выражения для подсчёта общего рейтинга по каждой формуле.
+// В запрос подставляются только отсюда, поэтому конкатенация безопасна
+var globalFormulas = map[string]string{
+	FormulaRanks:  "sum(r.norm_rank)",
+	FormulaScores: "sum(r.norm_score)",
+}
+
+// IsGlobalFormula проверяет, что такая формула общего рейтинга существует
+func IsGlobalFormula(formula string) bool {
+	_, ok := globalFormulas[formula]
+	return ok
+}
+
+// GlobalScoredUserModel пользователь в общем рейтинге по всем играм
+type GlobalScoredUserModel struct {
+	ID          int64
+	Username    string
+	PhotoUUID   sql.NullString
+	Active      bool
+	Rating      float64
+	Rank        int64
+	GamesPlayed int32
+}
+
+// GetPhotoUUID возвращает photoUUID или пустую строку, если его нет в базе
+func (u *GlobalScoredUserModel) GetPhotoUUID() string {
+	if u.PhotoUUID.Valid {
+		return u.PhotoUUID.String
+	}
+
+	return ""
+}
+
+// GetGlobalLeaderboard общий рейтинг игроков по всем опубликованным играм
+// (все строки таблицы games считаются опубликованными)
+func (gs *AccessObject) GetGlobalLeaderboard(ctx context.Context, formula string, limit, offset int) ([]*GlobalScoredUserModel, error) {
+	ctx = withQueryMethod(ctx, "GetGlobalLeaderboard")
+
+	ratingExpr, ok := globalFormulas[formula]
+	if !ok {
+		return nil, errors.Wrapf(utils.ErrInvalid, "unknown global leaderboard formula %q", formula)
+	}
+
+	//nolint: gosec выражение берётся только из globalFormulas
+	rows, err := gs.db.QueryContext(ctx, `WITH r AS (
+					SELECT ug.user_id,
+						1 - percent_rank() OVER (PARTITION BY ug.game_id ORDER BY ug.score DESC) AS norm_rank,
+						coalesce(ug.score::float8 / nullif(max(ug.score) OVER (PARTITION BY ug.game_id), 0), 0) AS norm_score
+					FROM visible_users_games ug JOIN games g ON g.id = ug.game_id
+				)
+				SELECT r.user_id, `+ratingExpr+` AS rating,
+					rank() OVER (ORDER BY `+ratingExpr+` DESC) AS place, count(*) AS games_played
+				FROM r GROUP BY r.user_id
+				ORDER BY rating DESC, r.user_id OFFSET $1 LIMIT $2;`, offset, limit)
+	if err != nil {
+		return nil, internalError(ctx, "get global leaderboard error: %v", err)
+	}
+	defer rows.Close()
+
+	IDs := make([]int64, 0)
+	leaderboard := make([]*GlobalScoredUserModel, 0)
+	for rows.Next() {
+		leader := &GlobalScoredUserModel{}
+		err = rows.Scan(&leader.ID, &leader.Rating, &leader.Rank, &leader.GamesPlayed)
+		if err != nil {
+			return nil, internalError(ctx, "get global leaderboard scan user error: %v", err)
+		}
+		leaderboard = append(leaderboard, leader)
+		IDs = append(IDs, leader.ID)
+	}
+
+	if len(leaderboard) == 0 {
+		return nil, utils.ErrNotExists
+	}
+
+	users, err := gs.getUsersInfo(ctx, IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leader := range leaderboard {
+		if user, ok := users[leader.ID]; ok {
+			leader.Username = user.Username
+			leader.Active = user.Active
+			leader.PhotoUUID = photoUUIDToNull(user.PhotoUUID)
+		}
+	}
+
+	return leaderboard, nil
+}
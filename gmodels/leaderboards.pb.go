@@ -0,0 +1,925 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: leaderboards.proto
+
+package gmodels
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type GlobalLeaderboardRequest struct {
+	Formula              string   `protobuf:"bytes,1,opt,name=formula,proto3" json:"formula,omitempty"`
+	Limit                int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset               int32    `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GlobalLeaderboardRequest) Reset()         { *m = GlobalLeaderboardRequest{} }
+func (m *GlobalLeaderboardRequest) String() string { return proto.CompactTextString(m) }
+func (*GlobalLeaderboardRequest) ProtoMessage()    {}
+func (*GlobalLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{0}
+}
+
+func (m *GlobalLeaderboardRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GlobalLeaderboardRequest.Unmarshal(m, b)
+}
+func (m *GlobalLeaderboardRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GlobalLeaderboardRequest.Marshal(b, m, deterministic)
+}
+func (m *GlobalLeaderboardRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GlobalLeaderboardRequest.Merge(m, src)
+}
+func (m *GlobalLeaderboardRequest) XXX_Size() int {
+	return xxx_messageInfo_GlobalLeaderboardRequest.Size(m)
+}
+func (m *GlobalLeaderboardRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GlobalLeaderboardRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GlobalLeaderboardRequest proto.InternalMessageInfo
+
+func (m *GlobalLeaderboardRequest) GetFormula() string {
+	if m != nil {
+		return m.Formula
+	}
+	return ""
+}
+
+func (m *GlobalLeaderboardRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *GlobalLeaderboardRequest) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type GlobalLeader struct {
+	ID                   int64    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	PhotoUUID            string   `protobuf:"bytes,3,opt,name=photoUUID,proto3" json:"photoUUID,omitempty"`
+	Active               bool     `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	Rating               float64  `protobuf:"fixed64,5,opt,name=rating,proto3" json:"rating,omitempty"`
+	Rank                 int64    `protobuf:"varint,6,opt,name=rank,proto3" json:"rank,omitempty"`
+	GamesPlayed          int32    `protobuf:"varint,7,opt,name=gamesPlayed,proto3" json:"gamesPlayed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GlobalLeader) Reset()         { *m = GlobalLeader{} }
+func (m *GlobalLeader) String() string { return proto.CompactTextString(m) }
+func (*GlobalLeader) ProtoMessage()    {}
+func (*GlobalLeader) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{1}
+}
+
+func (m *GlobalLeader) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GlobalLeader.Unmarshal(m, b)
+}
+func (m *GlobalLeader) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GlobalLeader.Marshal(b, m, deterministic)
+}
+func (m *GlobalLeader) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GlobalLeader.Merge(m, src)
+}
+func (m *GlobalLeader) XXX_Size() int {
+	return xxx_messageInfo_GlobalLeader.Size(m)
+}
+func (m *GlobalLeader) XXX_DiscardUnknown() {
+	xxx_messageInfo_GlobalLeader.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GlobalLeader proto.InternalMessageInfo
+
+func (m *GlobalLeader) GetID() int64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *GlobalLeader) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *GlobalLeader) GetPhotoUUID() string {
+	if m != nil {
+		return m.PhotoUUID
+	}
+	return ""
+}
+
+func (m *GlobalLeader) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *GlobalLeader) GetRating() float64 {
+	if m != nil {
+		return m.Rating
+	}
+	return 0
+}
+
+func (m *GlobalLeader) GetRank() int64 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+func (m *GlobalLeader) GetGamesPlayed() int32 {
+	if m != nil {
+		return m.GamesPlayed
+	}
+	return 0
+}
+
+type GlobalLeaderboard struct {
+	Leaders              []*GlobalLeader `protobuf:"bytes,1,rep,name=leaders,proto3" json:"leaders,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *GlobalLeaderboard) Reset()         { *m = GlobalLeaderboard{} }
+func (m *GlobalLeaderboard) String() string { return proto.CompactTextString(m) }
+func (*GlobalLeaderboard) ProtoMessage()    {}
+func (*GlobalLeaderboard) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{2}
+}
+
+func (m *GlobalLeaderboard) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GlobalLeaderboard.Unmarshal(m, b)
+}
+func (m *GlobalLeaderboard) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GlobalLeaderboard.Marshal(b, m, deterministic)
+}
+func (m *GlobalLeaderboard) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GlobalLeaderboard.Merge(m, src)
+}
+func (m *GlobalLeaderboard) XXX_Size() int {
+	return xxx_messageInfo_GlobalLeaderboard.Size(m)
+}
+func (m *GlobalLeaderboard) XXX_DiscardUnknown() {
+	xxx_messageInfo_GlobalLeaderboard.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GlobalLeaderboard proto.InternalMessageInfo
+
+func (m *GlobalLeaderboard) GetLeaders() []*GlobalLeader {
+	if m != nil {
+		return m.Leaders
+	}
+	return nil
+}
+
+type UserGamesRequest struct {
+	UserID               int64    `protobuf:"varint,1,opt,name=userID,proto3" json:"userID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UserGamesRequest) Reset()         { *m = UserGamesRequest{} }
+func (m *UserGamesRequest) String() string { return proto.CompactTextString(m) }
+func (*UserGamesRequest) ProtoMessage()    {}
+func (*UserGamesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{3}
+}
+
+func (m *UserGamesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserGamesRequest.Unmarshal(m, b)
+}
+func (m *UserGamesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserGamesRequest.Marshal(b, m, deterministic)
+}
+func (m *UserGamesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserGamesRequest.Merge(m, src)
+}
+func (m *UserGamesRequest) XXX_Size() int {
+	return xxx_messageInfo_UserGamesRequest.Size(m)
+}
+func (m *UserGamesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserGamesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserGamesRequest proto.InternalMessageInfo
+
+func (m *UserGamesRequest) GetUserID() int64 {
+	if m != nil {
+		return m.UserID
+	}
+	return 0
+}
+
+type UserGame struct {
+	Slug                 string   `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	Title                string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	BackgroundUUID       string   `protobuf:"bytes,3,opt,name=backgroundUUID,proto3" json:"backgroundUUID,omitempty"`
+	Score                int32    `protobuf:"varint,4,opt,name=score,proto3" json:"score,omitempty"`
+	Rank                 int64    `protobuf:"varint,5,opt,name=rank,proto3" json:"rank,omitempty"`
+	Percentile           float64  `protobuf:"fixed64,6,opt,name=percentile,proto3" json:"percentile,omitempty"`
+	LastPlayed           int64    `protobuf:"varint,7,opt,name=lastPlayed,proto3" json:"lastPlayed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UserGame) Reset()         { *m = UserGame{} }
+func (m *UserGame) String() string { return proto.CompactTextString(m) }
+func (*UserGame) ProtoMessage()    {}
+func (*UserGame) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{4}
+}
+
+func (m *UserGame) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserGame.Unmarshal(m, b)
+}
+func (m *UserGame) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserGame.Marshal(b, m, deterministic)
+}
+func (m *UserGame) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserGame.Merge(m, src)
+}
+func (m *UserGame) XXX_Size() int {
+	return xxx_messageInfo_UserGame.Size(m)
+}
+func (m *UserGame) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserGame.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserGame proto.InternalMessageInfo
+
+func (m *UserGame) GetSlug() string {
+	if m != nil {
+		return m.Slug
+	}
+	return ""
+}
+
+func (m *UserGame) GetTitle() string {
+	if m != nil {
+		return m.Title
+	}
+	return ""
+}
+
+func (m *UserGame) GetBackgroundUUID() string {
+	if m != nil {
+		return m.BackgroundUUID
+	}
+	return ""
+}
+
+func (m *UserGame) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *UserGame) GetRank() int64 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+func (m *UserGame) GetPercentile() float64 {
+	if m != nil {
+		return m.Percentile
+	}
+	return 0
+}
+
+func (m *UserGame) GetLastPlayed() int64 {
+	if m != nil {
+		return m.LastPlayed
+	}
+	return 0
+}
+
+type UserGames struct {
+	Games                []*UserGame `protobuf:"bytes,1,rep,name=games,proto3" json:"games,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *UserGames) Reset()         { *m = UserGames{} }
+func (m *UserGames) String() string { return proto.CompactTextString(m) }
+func (*UserGames) ProtoMessage()    {}
+func (*UserGames) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{5}
+}
+
+func (m *UserGames) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UserGames.Unmarshal(m, b)
+}
+func (m *UserGames) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UserGames.Marshal(b, m, deterministic)
+}
+func (m *UserGames) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UserGames.Merge(m, src)
+}
+func (m *UserGames) XXX_Size() int {
+	return xxx_messageInfo_UserGames.Size(m)
+}
+func (m *UserGames) XXX_DiscardUnknown() {
+	xxx_messageInfo_UserGames.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UserGames proto.InternalMessageInfo
+
+func (m *UserGames) GetGames() []*UserGame {
+	if m != nil {
+		return m.Games
+	}
+	return nil
+}
+
+type FriendsLeaderboardRequest struct {
+	Slug                 string   `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	UserIDs              []int64  `protobuf:"varint,2,rep,packed,name=userIDs,proto3" json:"userIDs,omitempty"`
+	FollowerID           int64    `protobuf:"varint,3,opt,name=followerID,proto3" json:"followerID,omitempty"`
+	Limit                int32    `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset               int32    `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FriendsLeaderboardRequest) Reset()         { *m = FriendsLeaderboardRequest{} }
+func (m *FriendsLeaderboardRequest) String() string { return proto.CompactTextString(m) }
+func (*FriendsLeaderboardRequest) ProtoMessage()    {}
+func (*FriendsLeaderboardRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{6}
+}
+
+func (m *FriendsLeaderboardRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FriendsLeaderboardRequest.Unmarshal(m, b)
+}
+func (m *FriendsLeaderboardRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FriendsLeaderboardRequest.Marshal(b, m, deterministic)
+}
+func (m *FriendsLeaderboardRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FriendsLeaderboardRequest.Merge(m, src)
+}
+func (m *FriendsLeaderboardRequest) XXX_Size() int {
+	return xxx_messageInfo_FriendsLeaderboardRequest.Size(m)
+}
+func (m *FriendsLeaderboardRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FriendsLeaderboardRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FriendsLeaderboardRequest proto.InternalMessageInfo
+
+func (m *FriendsLeaderboardRequest) GetSlug() string {
+	if m != nil {
+		return m.Slug
+	}
+	return ""
+}
+
+func (m *FriendsLeaderboardRequest) GetUserIDs() []int64 {
+	if m != nil {
+		return m.UserIDs
+	}
+	return nil
+}
+
+func (m *FriendsLeaderboardRequest) GetFollowerID() int64 {
+	if m != nil {
+		return m.FollowerID
+	}
+	return 0
+}
+
+func (m *FriendsLeaderboardRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *FriendsLeaderboardRequest) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type RankedUser struct {
+	ID                   int64    `protobuf:"varint,1,opt,name=ID,proto3" json:"ID,omitempty"`
+	Username             string   `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	PhotoUUID            string   `protobuf:"bytes,3,opt,name=photoUUID,proto3" json:"photoUUID,omitempty"`
+	Active               bool     `protobuf:"varint,4,opt,name=active,proto3" json:"active,omitempty"`
+	Score                int32    `protobuf:"varint,5,opt,name=score,proto3" json:"score,omitempty"`
+	Rank                 int64    `protobuf:"varint,6,opt,name=rank,proto3" json:"rank,omitempty"`
+	GlobalRank           int64    `protobuf:"varint,7,opt,name=globalRank,proto3" json:"globalRank,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RankedUser) Reset()         { *m = RankedUser{} }
+func (m *RankedUser) String() string { return proto.CompactTextString(m) }
+func (*RankedUser) ProtoMessage()    {}
+func (*RankedUser) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{7}
+}
+
+func (m *RankedUser) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RankedUser.Unmarshal(m, b)
+}
+func (m *RankedUser) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RankedUser.Marshal(b, m, deterministic)
+}
+func (m *RankedUser) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RankedUser.Merge(m, src)
+}
+func (m *RankedUser) XXX_Size() int {
+	return xxx_messageInfo_RankedUser.Size(m)
+}
+func (m *RankedUser) XXX_DiscardUnknown() {
+	xxx_messageInfo_RankedUser.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RankedUser proto.InternalMessageInfo
+
+func (m *RankedUser) GetID() int64 {
+	if m != nil {
+		return m.ID
+	}
+	return 0
+}
+
+func (m *RankedUser) GetUsername() string {
+	if m != nil {
+		return m.Username
+	}
+	return ""
+}
+
+func (m *RankedUser) GetPhotoUUID() string {
+	if m != nil {
+		return m.PhotoUUID
+	}
+	return ""
+}
+
+func (m *RankedUser) GetActive() bool {
+	if m != nil {
+		return m.Active
+	}
+	return false
+}
+
+func (m *RankedUser) GetScore() int32 {
+	if m != nil {
+		return m.Score
+	}
+	return 0
+}
+
+func (m *RankedUser) GetRank() int64 {
+	if m != nil {
+		return m.Rank
+	}
+	return 0
+}
+
+func (m *RankedUser) GetGlobalRank() int64 {
+	if m != nil {
+		return m.GlobalRank
+	}
+	return 0
+}
+
+type RankedLeaderboard struct {
+	Users                []*RankedUser `protobuf:"bytes,1,rep,name=users,proto3" json:"users,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *RankedLeaderboard) Reset()         { *m = RankedLeaderboard{} }
+func (m *RankedLeaderboard) String() string { return proto.CompactTextString(m) }
+func (*RankedLeaderboard) ProtoMessage()    {}
+func (*RankedLeaderboard) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{8}
+}
+
+func (m *RankedLeaderboard) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RankedLeaderboard.Unmarshal(m, b)
+}
+func (m *RankedLeaderboard) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RankedLeaderboard.Marshal(b, m, deterministic)
+}
+func (m *RankedLeaderboard) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RankedLeaderboard.Merge(m, src)
+}
+func (m *RankedLeaderboard) XXX_Size() int {
+	return xxx_messageInfo_RankedLeaderboard.Size(m)
+}
+func (m *RankedLeaderboard) XXX_DiscardUnknown() {
+	xxx_messageInfo_RankedLeaderboard.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RankedLeaderboard proto.InternalMessageInfo
+
+func (m *RankedLeaderboard) GetUsers() []*RankedUser {
+	if m != nil {
+		return m.Users
+	}
+	return nil
+}
+
+type ScoreUpdate struct {
+	Slug                 string   `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	UserID               int64    `protobuf:"varint,2,opt,name=userID,proto3" json:"userID,omitempty"`
+	Delta                int32    `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	Reason               string   `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Source               string   `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	MatchID              string   `protobuf:"bytes,6,opt,name=matchID,proto3" json:"matchID,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ScoreUpdate) Reset()         { *m = ScoreUpdate{} }
+func (m *ScoreUpdate) String() string { return proto.CompactTextString(m) }
+func (*ScoreUpdate) ProtoMessage()    {}
+func (*ScoreUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{9}
+}
+
+func (m *ScoreUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScoreUpdate.Unmarshal(m, b)
+}
+func (m *ScoreUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScoreUpdate.Marshal(b, m, deterministic)
+}
+func (m *ScoreUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScoreUpdate.Merge(m, src)
+}
+func (m *ScoreUpdate) XXX_Size() int {
+	return xxx_messageInfo_ScoreUpdate.Size(m)
+}
+func (m *ScoreUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScoreUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScoreUpdate proto.InternalMessageInfo
+
+func (m *ScoreUpdate) GetSlug() string {
+	if m != nil {
+		return m.Slug
+	}
+	return ""
+}
+
+func (m *ScoreUpdate) GetUserID() int64 {
+	if m != nil {
+		return m.UserID
+	}
+	return 0
+}
+
+func (m *ScoreUpdate) GetDelta() int32 {
+	if m != nil {
+		return m.Delta
+	}
+	return 0
+}
+
+func (m *ScoreUpdate) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ScoreUpdate) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *ScoreUpdate) GetMatchID() string {
+	if m != nil {
+		return m.MatchID
+	}
+	return ""
+}
+
+type UpdatedScore struct {
+	OldScore             int32    `protobuf:"varint,1,opt,name=oldScore,proto3" json:"oldScore,omitempty"`
+	NewScore             int32    `protobuf:"varint,2,opt,name=newScore,proto3" json:"newScore,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UpdatedScore) Reset()         { *m = UpdatedScore{} }
+func (m *UpdatedScore) String() string { return proto.CompactTextString(m) }
+func (*UpdatedScore) ProtoMessage()    {}
+func (*UpdatedScore) Descriptor() ([]byte, []int) {
+	return fileDescriptor_22f87181aaf7487a, []int{10}
+}
+
+func (m *UpdatedScore) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UpdatedScore.Unmarshal(m, b)
+}
+func (m *UpdatedScore) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UpdatedScore.Marshal(b, m, deterministic)
+}
+func (m *UpdatedScore) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UpdatedScore.Merge(m, src)
+}
+func (m *UpdatedScore) XXX_Size() int {
+	return xxx_messageInfo_UpdatedScore.Size(m)
+}
+func (m *UpdatedScore) XXX_DiscardUnknown() {
+	xxx_messageInfo_UpdatedScore.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UpdatedScore proto.InternalMessageInfo
+
+func (m *UpdatedScore) GetOldScore() int32 {
+	if m != nil {
+		return m.OldScore
+	}
+	return 0
+}
+
+func (m *UpdatedScore) GetNewScore() int32 {
+	if m != nil {
+		return m.NewScore
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*GlobalLeaderboardRequest)(nil), "gmodels.GlobalLeaderboardRequest")
+	proto.RegisterType((*GlobalLeader)(nil), "gmodels.GlobalLeader")
+	proto.RegisterType((*GlobalLeaderboard)(nil), "gmodels.GlobalLeaderboard")
+	proto.RegisterType((*UserGamesRequest)(nil), "gmodels.UserGamesRequest")
+	proto.RegisterType((*UserGame)(nil), "gmodels.UserGame")
+	proto.RegisterType((*UserGames)(nil), "gmodels.UserGames")
+	proto.RegisterType((*FriendsLeaderboardRequest)(nil), "gmodels.FriendsLeaderboardRequest")
+	proto.RegisterType((*RankedUser)(nil), "gmodels.RankedUser")
+	proto.RegisterType((*RankedLeaderboard)(nil), "gmodels.RankedLeaderboard")
+	proto.RegisterType((*ScoreUpdate)(nil), "gmodels.ScoreUpdate")
+	proto.RegisterType((*UpdatedScore)(nil), "gmodels.UpdatedScore")
+}
+
+func init() { proto.RegisterFile("leaderboards.proto", fileDescriptor_22f87181aaf7487a) }
+
+var fileDescriptor_22f87181aaf7487a = []byte{
+	// 663 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x55, 0xcf, 0x6f, 0xd3, 0x30,
+	0x14, 0x96, 0x9b, 0xa5, 0x5d, 0x5e, 0xab, 0x89, 0x99, 0x6d, 0xca, 0x22, 0x84, 0x42, 0x0e, 0x50,
+	0x38, 0x0c, 0x69, 0x70, 0x42, 0x82, 0x53, 0xb5, 0xaa, 0x12, 0x07, 0x64, 0x54, 0x71, 0x76, 0x1b,
+	0xb7, 0x8b, 0xe6, 0xc6, 0xc5, 0x76, 0x99, 0xf8, 0x4b, 0x10, 0xff, 0x09, 0x5c, 0x38, 0xf2, 0x77,
+	0x21, 0xdb, 0xf9, 0xe1, 0xad, 0xd9, 0x95, 0x9b, 0xbf, 0xe7, 0x17, 0xfb, 0xbd, 0xef, 0xfb, 0x9e,
+	0x03, 0x98, 0x33, 0x9a, 0x33, 0xb9, 0x10, 0x54, 0xe6, 0xea, 0x62, 0x2b, 0x85, 0x16, 0x78, 0xb0,
+	0xde, 0x88, 0x9c, 0x71, 0x95, 0x2d, 0x20, 0x9e, 0x72, 0xb1, 0xa0, 0xfc, 0x63, 0x9b, 0x44, 0xd8,
+	0xd7, 0x1d, 0x53, 0x1a, 0xc7, 0x30, 0x58, 0x09, 0xb9, 0xd9, 0x71, 0x1a, 0xa3, 0x14, 0x8d, 0x23,
+	0x52, 0x43, 0x7c, 0x02, 0x21, 0x2f, 0x36, 0x85, 0x8e, 0x7b, 0x29, 0x1a, 0x87, 0xc4, 0x01, 0x7c,
+	0x06, 0x7d, 0xb1, 0x5a, 0x29, 0xa6, 0xe3, 0xc0, 0x86, 0x2b, 0x94, 0xfd, 0x41, 0x30, 0xf2, 0x2f,
+	0xc1, 0x47, 0xd0, 0x9b, 0x4d, 0xec, 0x99, 0x01, 0xe9, 0xcd, 0x26, 0x38, 0x81, 0xc3, 0x9d, 0x62,
+	0xb2, 0xa4, 0x1b, 0x66, 0x4f, 0x8c, 0x48, 0x83, 0xf1, 0x13, 0x88, 0xb6, 0xd7, 0x42, 0x8b, 0xf9,
+	0x7c, 0x36, 0xb1, 0xe7, 0x46, 0xa4, 0x0d, 0x98, 0x2b, 0xe9, 0x52, 0x17, 0xdf, 0x58, 0x7c, 0x90,
+	0xa2, 0xf1, 0x21, 0xa9, 0x90, 0x89, 0x4b, 0xaa, 0x8b, 0x72, 0x1d, 0x87, 0x29, 0x1a, 0x23, 0x52,
+	0x21, 0x8c, 0xe1, 0x40, 0xd2, 0xf2, 0x26, 0xee, 0xdb, 0xbb, 0xed, 0x1a, 0xa7, 0x30, 0x5c, 0xd3,
+	0x0d, 0x53, 0x9f, 0x38, 0xfd, 0xce, 0xf2, 0x78, 0x60, 0x6b, 0xf7, 0x43, 0xd9, 0x04, 0x8e, 0xf7,
+	0x48, 0xc2, 0xaf, 0x61, 0xe0, 0x88, 0x55, 0x31, 0x4a, 0x83, 0xf1, 0xf0, 0xf2, 0xf4, 0xa2, 0x22,
+	0xf5, 0xc2, 0x4f, 0x26, 0x75, 0x56, 0xf6, 0x0a, 0x1e, 0xcd, 0x15, 0x93, 0x53, 0x73, 0x70, 0x4d,
+	0xf1, 0x19, 0xf4, 0x4d, 0xa7, 0x0d, 0x1b, 0x15, 0xca, 0xfe, 0x22, 0x38, 0xac, 0x93, 0x4d, 0xd1,
+	0x8a, 0xef, 0xd6, 0x95, 0x08, 0x76, 0x6d, 0x14, 0xd0, 0x85, 0xe6, 0x35, 0x5f, 0x0e, 0xe0, 0xe7,
+	0x70, 0xb4, 0xa0, 0xcb, 0x9b, 0xb5, 0x14, 0xbb, 0x32, 0xf7, 0x18, 0xbb, 0x17, 0x35, 0x5f, 0xab,
+	0xa5, 0x90, 0x8e, 0xb5, 0x90, 0x38, 0xd0, 0x90, 0x13, 0x7a, 0xe4, 0x3c, 0x05, 0xd8, 0x32, 0xb9,
+	0x64, 0xa5, 0x2e, 0x38, 0xb3, 0xb4, 0x21, 0xe2, 0x45, 0xcc, 0x3e, 0xa7, 0x4a, 0x7b, 0xdc, 0x05,
+	0xc4, 0x8b, 0x64, 0x6f, 0x21, 0x6a, 0x9a, 0xc6, 0x2f, 0x20, 0xb4, 0xb4, 0x56, 0x84, 0x1d, 0x37,
+	0x84, 0xd5, 0x29, 0xc4, 0xed, 0x67, 0x3f, 0x10, 0x9c, 0x5f, 0xc9, 0x82, 0x95, 0xb9, 0xea, 0xf0,
+	0x65, 0x17, 0x1f, 0x31, 0x0c, 0x1c, 0x75, 0x2a, 0xee, 0xa5, 0xc1, 0x38, 0x20, 0x35, 0x34, 0x15,
+	0xae, 0x04, 0xe7, 0xe2, 0x96, 0xc9, 0x8a, 0x8f, 0x80, 0x78, 0x91, 0xd6, 0xcb, 0x07, 0xdd, 0x5e,
+	0x0e, 0xef, 0x78, 0xf9, 0x17, 0x02, 0x20, 0xb4, 0xbc, 0x61, 0xf9, 0x5c, 0xfd, 0x17, 0x27, 0x37,
+	0x52, 0x85, 0x5d, 0x52, 0xf5, 0xef, 0x4a, 0xb5, 0xb6, 0xc6, 0x33, 0xf5, 0xd5, 0x52, 0xb4, 0x91,
+	0xec, 0x03, 0x1c, 0xbb, 0xca, 0x7d, 0x17, 0xbf, 0x84, 0xd0, 0x14, 0x58, 0x4b, 0xf2, 0xb8, 0x91,
+	0xa4, 0x6d, 0x92, 0xb8, 0x8c, 0xec, 0x27, 0x82, 0xe1, 0x67, 0x73, 0xfb, 0x7c, 0x9b, 0x53, 0xdd,
+	0x6d, 0xcb, 0xd6, 0xcf, 0x3d, 0xdf, 0xcf, 0xa6, 0x8b, 0x9c, 0x71, 0x4d, 0xab, 0x97, 0xc1, 0x01,
+	0x93, 0x2d, 0x19, 0x55, 0xa2, 0xb4, 0x3d, 0x47, 0xa4, 0x42, 0x26, 0xae, 0xc4, 0x4e, 0x2e, 0x5d,
+	0xd3, 0x11, 0xa9, 0x90, 0x11, 0x79, 0x43, 0xf5, 0xf2, 0x7a, 0x36, 0xb1, 0x8d, 0x47, 0xa4, 0x86,
+	0xd9, 0x15, 0x8c, 0x5c, 0x55, 0xb9, 0xad, 0xd0, 0xe8, 0x20, 0xb8, 0x5b, 0xdb, 0xfa, 0x42, 0xd2,
+	0x60, 0xb3, 0x57, 0xb2, 0x5b, 0xb7, 0xe7, 0xde, 0xaf, 0x06, 0x5f, 0xfe, 0xee, 0xc1, 0xc8, 0xa3,
+	0x47, 0xe1, 0x39, 0x9c, 0x4c, 0x99, 0xde, 0x9f, 0xfe, 0x67, 0x9d, 0xc3, 0xee, 0xdb, 0x34, 0x49,
+	0x1e, 0x4e, 0xc1, 0xef, 0x61, 0x34, 0x65, 0xba, 0x9d, 0x8c, 0xf3, 0xbd, 0x51, 0xa8, 0x9f, 0x88,
+	0x04, 0xef, 0x6f, 0xe1, 0x2f, 0x70, 0x3a, 0x65, 0x7a, 0x7f, 0x42, 0x70, 0xd6, 0x24, 0x3f, 0x38,
+	0x3e, 0x49, 0x72, 0x4f, 0x63, 0xff, 0xfb, 0x77, 0x30, 0x74, 0x3c, 0x3a, 0xaa, 0x4e, 0x9a, 0x54,
+	0x4f, 0xf8, 0xa4, 0x7d, 0xe8, 0x7c, 0xce, 0x17, 0x7d, 0xfb, 0x6b, 0x79, 0xf3, 0x6f, 0x00, 0x30,
+	0xe3, 0x58, 0xb6, 0x70, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// LeaderboardsClient is the client API for Leaderboards service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type LeaderboardsClient interface {
+	GetGlobalLeaderboard(ctx context.Context, in *GlobalLeaderboardRequest, opts ...grpc.CallOption) (*GlobalLeaderboard, error)
+	GetUserGames(ctx context.Context, in *UserGamesRequest, opts ...grpc.CallOption) (*UserGames, error)
+	GetFriendsLeaderboard(ctx context.Context, in *FriendsLeaderboardRequest, opts ...grpc.CallOption) (*RankedLeaderboard, error)
+	UpdateScore(ctx context.Context, in *ScoreUpdate, opts ...grpc.CallOption) (*UpdatedScore, error)
+}
+
+type leaderboardsClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewLeaderboardsClient(cc *grpc.ClientConn) LeaderboardsClient {
+	return &leaderboardsClient{cc}
+}
+
+func (c *leaderboardsClient) GetGlobalLeaderboard(ctx context.Context, in *GlobalLeaderboardRequest, opts ...grpc.CallOption) (*GlobalLeaderboard, error) {
+	out := new(GlobalLeaderboard)
+	err := c.cc.Invoke(ctx, "/gmodels.Leaderboards/GetGlobalLeaderboard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardsClient) GetUserGames(ctx context.Context, in *UserGamesRequest, opts ...grpc.CallOption) (*UserGames, error) {
+	out := new(UserGames)
+	err := c.cc.Invoke(ctx, "/gmodels.Leaderboards/GetUserGames", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardsClient) GetFriendsLeaderboard(ctx context.Context, in *FriendsLeaderboardRequest, opts ...grpc.CallOption) (*RankedLeaderboard, error) {
+	out := new(RankedLeaderboard)
+	err := c.cc.Invoke(ctx, "/gmodels.Leaderboards/GetFriendsLeaderboard", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaderboardsClient) UpdateScore(ctx context.Context, in *ScoreUpdate, opts ...grpc.CallOption) (*UpdatedScore, error) {
+	out := new(UpdatedScore)
+	err := c.cc.Invoke(ctx, "/gmodels.Leaderboards/UpdateScore", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LeaderboardsServer is the server API for Leaderboards service.
+type LeaderboardsServer interface {
+	GetGlobalLeaderboard(context.Context, *GlobalLeaderboardRequest) (*GlobalLeaderboard, error)
+	GetUserGames(context.Context, *UserGamesRequest) (*UserGames, error)
+	GetFriendsLeaderboard(context.Context, *FriendsLeaderboardRequest) (*RankedLeaderboard, error)
+	UpdateScore(context.Context, *ScoreUpdate) (*UpdatedScore, error)
+}
+
+func RegisterLeaderboardsServer(s *grpc.Server, srv LeaderboardsServer) {
+	s.RegisterService(&_Leaderboards_serviceDesc, srv)
+}
+
+func _Leaderboards_GetGlobalLeaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GlobalLeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardsServer).GetGlobalLeaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gmodels.Leaderboards/GetGlobalLeaderboard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardsServer).GetGlobalLeaderboard(ctx, req.(*GlobalLeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboards_GetUserGames_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserGamesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardsServer).GetUserGames(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gmodels.Leaderboards/GetUserGames",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardsServer).GetUserGames(ctx, req.(*UserGamesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboards_GetFriendsLeaderboard_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FriendsLeaderboardRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardsServer).GetFriendsLeaderboard(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gmodels.Leaderboards/GetFriendsLeaderboard",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardsServer).GetFriendsLeaderboard(ctx, req.(*FriendsLeaderboardRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Leaderboards_UpdateScore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScoreUpdate)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaderboardsServer).UpdateScore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gmodels.Leaderboards/UpdateScore",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaderboardsServer).UpdateScore(ctx, req.(*ScoreUpdate))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Leaderboards_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gmodels.Leaderboards",
+	HandlerType: (*LeaderboardsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGlobalLeaderboard",
+			Handler:    _Leaderboards_GetGlobalLeaderboard_Handler,
+		},
+		{
+			MethodName: "GetUserGames",
+			Handler:    _Leaderboards_GetUserGames_Handler,
+		},
+		{
+			MethodName: "GetFriendsLeaderboard",
+			Handler:    _Leaderboards_GetFriendsLeaderboard_Handler,
+		},
+		{
+			MethodName: "UpdateScore",
+			Handler:    _Leaderboards_UpdateScore_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "leaderboards.proto",
+}
@@ -6,6 +6,7 @@ require (
 	github.com/DATA-DOG/go-sqlmock v1.3.3
 	github.com/HotCodeGroup/warscript-utils v0.0.0-20190525134135-f9addc69c0b4
 	github.com/go-park-mail-ru/2019_1_HotCode v0.0.0-20190426172604-1d3ce9818cea
+	github.com/golang/protobuf v1.3.1
 	github.com/google/uuid v1.1.1
 	github.com/gorilla/mux v1.7.1
 	github.com/hashicorp/consul/api v1.0.1
@@ -16,7 +17,10 @@ require (
 	github.com/mailru/easyjson v0.0.0-20190312143242-1de009706dbe
 	github.com/pkg/errors v0.8.1
 	github.com/prometheus/client_golang v0.9.2
+	github.com/prometheus/client_model v0.0.0-20180712105110-5c3871d89910
 	github.com/sirupsen/logrus v1.4.1
 	google.golang.org/appengine v1.4.0 // indirect
+	google.golang.org/genproto v0.0.0-20190404172233-64821d5d2107
 	google.golang.org/grpc v1.20.1
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -3,16 +3,25 @@ package main
 import (
 	"context"
 
+	"github.com/HotCodeGroup/warscript-games/gmodels"
 	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/HotCodeGroup/warscript-utils/utils"
 	"github.com/pkg/errors"
 )
 
 // GamesManager реализация GRPC сервера
-type GamesManager struct{}
+// (сервисы models.Games и gmodels.Leaderboards). Ошибки в статусы gRPC
+// переводит grpcErrorInterceptor
+type GamesManager struct {
+	srv *Server
+}
 
 // GetGameBySlug отдаёт информацию о игре по заданному slug
 func (gm *GamesManager) GetGameBySlug(ctx context.Context, gameSlug *models.GameSlug) (*models.InfoGame, error) {
-	game, err := getGameBySlugImpl(gameSlug.Slug)
+	ctx, cancel := gm.srv.queryContext(ctx)
+	defer cancel()
+
+	game, err := gm.srv.getGameBySlugImpl(ctx, gameSlug.Slug)
 	if err != nil {
 		return nil, errors.Wrap(err, "can not get game by slug")
 	}
@@ -28,3 +37,140 @@ func (gm *GamesManager) GetGameBySlug(ctx context.Context, gameSlug *models.Game
 		BackgroundUUID: game.BackgroundUUID,
 	}, nil
 }
+
+// GetGlobalLeaderboard отдаёт общий рейтинг игроков по всем играм
+func (gm *GamesManager) GetGlobalLeaderboard(ctx context.Context,
+	req *gmodels.GlobalLeaderboardRequest) (*gmodels.GlobalLeaderboard, error) {
+	ctx, cancel := gm.srv.queryContext(ctx)
+	defer cancel()
+
+	limit, offset, err := pageParams(req.Limit, req.Offset, defaultLeaderboardLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get global leaderboard")
+	}
+
+	leadersModels, err := gm.srv.getGlobalLeaderboardImpl(ctx, req.Formula, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get global leaderboard")
+	}
+
+	leaders := make([]*gmodels.GlobalLeader, len(leadersModels))
+	for i, leader := range leadersModels {
+		leaders[i] = &gmodels.GlobalLeader{
+			ID:          leader.ID,
+			Username:    leader.Username,
+			PhotoUUID:   leader.GetPhotoUUID(),
+			Active:      leader.Active,
+			Rating:      leader.Rating,
+			Rank:        leader.Rank,
+			GamesPlayed: leader.GamesPlayed,
+		}
+	}
+
+	return &gmodels.GlobalLeaderboard{
+		Leaders: leaders,
+	}, nil
+}
+
+// GetUserGames отдаёт игры пользователя с его местами в них
+func (gm *GamesManager) GetUserGames(ctx context.Context, req *gmodels.UserGamesRequest) (*gmodels.UserGames, error) {
+	ctx, cancel := gm.srv.queryContext(ctx)
+	defer cancel()
+
+	userGamesModels, err := gm.srv.games.GetUserGames(ctx, req.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get user games")
+	}
+
+	userGames := make([]*gmodels.UserGame, len(userGamesModels))
+	for i, ug := range userGamesModels {
+		userGames[i] = &gmodels.UserGame{
+			Slug:           ug.Slug,
+			Title:          ug.Title,
+			BackgroundUUID: ug.GetBackgroundUUID(),
+			Score:          ug.Score,
+			Rank:           ug.Rank,
+			Percentile:     ug.Percentile,
+			LastPlayed:     ug.LastPlayed.Unix(),
+		}
+	}
+
+	return &gmodels.UserGames{
+		Games: userGames,
+	}, nil
+}
+
+// GetFriendsLeaderboard отдаёт leaderboard игры только среди выбранных игроков
+func (gm *GamesManager) GetFriendsLeaderboard(ctx context.Context,
+	req *gmodels.FriendsLeaderboardRequest) (*gmodels.RankedLeaderboard, error) {
+	ctx, cancel := gm.srv.queryContext(ctx)
+	defer cancel()
+
+	limit, offset, err := pageParams(req.Limit, req.Offset, defaultLeaderboardLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get friends leaderboard")
+	}
+
+	rankedModels, err := gm.srv.getLeaderboardForUsersImpl(ctx, req.Slug, req.UserIDs, req.FollowerID,
+		limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not get friends leaderboard")
+	}
+
+	ranked := make([]*gmodels.RankedUser, len(rankedModels))
+	for i, rankedUser := range rankedModels {
+		ranked[i] = &gmodels.RankedUser{
+			ID:         rankedUser.ID,
+			Username:   rankedUser.Username,
+			PhotoUUID:  rankedUser.GetPhotoUUID(),
+			Active:     rankedUser.Active,
+			Score:      rankedUser.Score,
+			Rank:       rankedUser.Rank,
+			GlobalRank: rankedUser.GlobalRank,
+		}
+	}
+
+	return &gmodels.RankedLeaderboard{
+		Users: ranked,
+	}, nil
+}
+
+// UpdateScore меняет очки пользователя и пишет изменение в историю.
+// Сервисы вызывают его с токеном из ServiceTokens в x-service-token;
+// вызов от имени пользователя (с сессией в x-session-token) -- ручная
+// правка, и она доступна только модераторам. Без того и другого -- Unauthenticated
+func (gm *GamesManager) UpdateScore(ctx context.Context, req *gmodels.ScoreUpdate) (*gmodels.UpdatedScore, error) {
+	if !gm.srv.grpcService(ctx) {
+		user, err := gm.srv.grpcUser(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "can not check session")
+		}
+		if err = authorize(withUser(ctx, user), permModerateScores, req.Slug); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := gm.srv.queryContext(ctx)
+	defer cancel()
+
+	if req.Source == "" {
+		return nil, &utils.ValidationError{
+			"source": utils.ErrRequired.Error(),
+		}
+	}
+
+	oldScore, newScore, err := gm.srv.games.UpdateUserScore(ctx, req.Slug, req.UserID, &ScoreChange{
+		Delta:   req.Delta,
+		Reason:  req.Reason,
+		Source:  req.Source,
+		MatchID: req.MatchID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "can not update score")
+	}
+
+	return &gmodels.UpdatedScore{
+		OldScore: oldScore,
+		NewScore: newScore,
+	}, nil
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"sort"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// gameRequest запросы, которые ссылаются на игру по slug
+type gameRequest interface {
+	GetSlug() string
+}
+
+// grpcErrorInterceptor переводит ошибки методов GamesManager в статусы gRPC,
+// чтобы клиент мог отличить отсутствующую игру от ошибки валидации и сбоя
+func grpcErrorInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, grpcStatusError(req, err)
+	}
+
+	return resp, nil
+}
+
+// grpcStatusError статус с деталями для ошибки err, которую вернул метод
+// на запрос req. Уже готовые статусы отдаются как есть
+func grpcStatusError(req interface{}, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	cause := errors.Cause(err)
+	if validErr, ok := cause.(*utils.ValidationError); ok {
+		return withDetails(status.New(codes.InvalidArgument, err.Error()), badRequest(validErr))
+	}
+
+	switch cause {
+	case utils.ErrNotExists:
+		st := status.New(codes.NotFound, err.Error())
+		if r, ok := req.(gameRequest); ok {
+			return withDetails(st, &errdetails.ResourceInfo{
+				ResourceType: "game",
+				ResourceName: r.GetSlug(),
+				Description:  err.Error(),
+			})
+		}
+		return st.Err()
+	case utils.ErrInvalid:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errUnauthorized:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errUsersUnavailable:
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+// badRequest поля с ошибками валидации в стабильном порядке
+func badRequest(validErr *utils.ValidationError) *errdetails.BadRequest {
+	fields := make([]string, 0, len(*validErr))
+	for field := range *validErr {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	details := &errdetails.BadRequest{}
+	for _, field := range fields {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: (*validErr)[field],
+		})
+	}
+
+	return details
+}
+
+// withDetails статус с деталями; если детали не сериализовались,
+// отдаём статус без них, но с правильным кодом
+func withDetails(st *status.Status, details ...proto.Message) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
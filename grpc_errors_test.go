@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+func TestGRPCErrorInterceptor(t *testing.T) {
+	cases := []struct {
+		req             interface{}
+		err             error
+		expectedCode    codes.Code
+		expectedDetails []proto.Message
+	}{
+		{
+			req:          &models.GameSlug{Slug: "pong"},
+			expectedCode: codes.OK,
+		},
+		{
+			req:          &models.GameSlug{Slug: "ping-pong"},
+			err:          errors.Wrap(utils.ErrNotExists, "can not get game by slug"),
+			expectedCode: codes.NotFound,
+			expectedDetails: []proto.Message{&errdetails.ResourceInfo{
+				ResourceType: "game",
+				ResourceName: "ping-pong",
+				Description:  "can not get game by slug: not_exists",
+			}},
+		},
+		{
+			req: &models.GameSlug{Slug: "pong"},
+			err: errors.Wrap(&utils.ValidationError{
+				"source": utils.ErrRequired.Error(),
+				"delta":  utils.ErrInvalid.Error(),
+			}, "can not update score"),
+			expectedCode: codes.InvalidArgument,
+			expectedDetails: []proto.Message{&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: "delta", Description: "invalid"},
+					{Field: "source", Description: "required"},
+				},
+			}},
+		},
+		{
+			req:          &models.GameSlug{Slug: "pong"},
+			err:          errors.Wrap(errTimeout, "can not get game by slug"),
+			expectedCode: codes.DeadlineExceeded,
+		},
+		{
+			req:          &models.GameSlug{Slug: "pong"},
+			err:          errors.Wrap(utils.ErrInternal, "can not get game by slug"),
+			expectedCode: codes.Internal,
+		},
+		{ // готовый статус не трогаем
+			req:          &models.GameSlug{Slug: "pong"},
+			err:          status.Error(codes.Unavailable, "warscript-users is not configured"),
+			expectedCode: codes.Unavailable,
+		},
+	}
+
+	for i, c := range cases {
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, c.err
+		}
+
+		_, err := grpcErrorInterceptor(context.Background(), c.req, &grpc.UnaryServerInfo{}, handler)
+		st := status.Convert(err)
+		if st.Code() != c.expectedCode {
+			t.Errorf("[%d] TestGRPCErrorInterceptor got code %s, expected %s", i, st.Code(), c.expectedCode)
+		}
+
+		details := make([]proto.Message, 0)
+		for _, d := range st.Details() {
+			details = append(details, d.(proto.Message))
+		}
+		if len(details) != len(c.expectedDetails) {
+			t.Errorf("[%d] TestGRPCErrorInterceptor got details %v, expected %v", i, details, c.expectedDetails)
+			continue
+		}
+		for j := range details {
+			if !proto.Equal(details[j], c.expectedDetails[j]) {
+				t.Errorf("[%d] TestGRPCErrorInterceptor got detail %v, expected %v", i, details[j], c.expectedDetails[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/sirupsen/logrus"
+)
+
+// requestIDHeader метаданные gRPC с ID запроса
+const requestIDHeader = "x-request-id"
+
+var (
+	grpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_requests_total",
+		Help: "Количество gRPC запросов по методам и кодам ответа",
+	}, []string{"method", "code"})
+	grpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_request_duration_seconds",
+		Help:    "Время обработки gRPC запросов по методам",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+func init() {
+	prometheus.MustRegister(grpcRequests, grpcDuration)
+}
+
+// grpcServerOptions interceptors нашего gRPC сервера. Порядок снаружи внутрь:
+// ID запроса, спан, лог, метрики, паники, перевод ошибок в статусы -- так лог
+// и метрики видят итоговый код, в том числе после паники
+func (s *Server) grpcServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.unaryInterceptor()),
+		grpc.StreamInterceptor(s.streamInterceptor()),
+	}
+}
+
+func (s *Server) unaryInterceptor() grpc.UnaryServerInterceptor {
+	return chainUnaryInterceptors(
+		unaryRequestID,
+		unaryTracing(s.tracer),
+		unaryAccessLog(s.logger),
+		unaryMetrics,
+		unaryRecover(s.logger),
+		grpcErrorInterceptor,
+	)
+}
+
+func (s *Server) streamInterceptor() grpc.StreamServerInterceptor {
+	return chainStreamInterceptors(
+		streamRequestID,
+		streamTracing(s.tracer),
+		streamAccessLog(s.logger),
+		streamMetrics,
+		streamRecover(s.logger),
+	)
+}
+
+// chainUnaryInterceptors в grpc 1.20 ещё нет ChainUnaryInterceptor, а
+// UnaryInterceptor можно задать только один. Первый в списке -- самый внешний
+func chainUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+
+		return chained(ctx, req)
+	}
+}
+
+// chainStreamInterceptors то же для стримов
+func chainStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		chained := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, next := interceptors[i], chained
+			chained = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+
+		return chained(srv, ss)
+	}
+}
+
+// contextStream стрим с подменённым контекстом
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// requestID ID запроса из контекста: его кладут AccessLogMiddleware
+// для http и unaryRequestID/streamRequestID для gRPC
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(utils.RequestUUIDKey).(string); ok {
+		return id
+	}
+
+	return ""
+}
+
+// withRequestID берёт ID из входящих метаданных, а если его нет --
+// создаёт такой же, как для http, и кладёт в контекст
+func withRequestID(ctx context.Context) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) != 0 {
+			id = values[0]
+		}
+	}
+	if id == "" {
+		id = uuid.New().String()[:8]
+	}
+
+	return context.WithValue(ctx, utils.RequestUUIDKey, id), id
+}
+
+// outgoingRequestID передаёт ID текущего запроса дальше, в warscript-users
+func outgoingRequestID(ctx context.Context) context.Context {
+	if id := requestID(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, requestIDHeader, id)
+	}
+
+	return ctx
+}
+
+// unaryRequestID ID запроса в контексте и в заголовке ответа
+func unaryRequestID(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, id := withRequestID(ctx)
+	//nolint: errcheck
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+
+	return handler(ctx, req)
+}
+
+func streamRequestID(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	ctx, id := withRequestID(ss.Context())
+	//nolint: errcheck
+	ss.SetHeader(metadata.Pairs(requestIDHeader, id))
+
+	return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+}
+
+// logGRPCCall пишет строку access лога в том же формате, что и AccessLogMiddleware
+func logGRPCCall(ctx context.Context, logger *logrus.Logger, method string, start time.Time, err error) {
+	entry := logger.WithFields(logrus.Fields{
+		"token":     requestID(ctx),
+		"status":    status.Code(err).String(),
+		"method":    "gRPC",
+		"work_time": time.Since(start).Seconds(),
+	})
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+
+	entry.Info(method)
+}
+
+// unaryAccessLog логирование всех gRPC запросов
+func unaryAccessLog(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logGRPCCall(ctx, logger, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+func streamAccessLog(logger *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logGRPCCall(ss.Context(), logger, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// observeGRPCCall количество и время запросов по методам
+func observeGRPCCall(method string, start time.Time, err error) {
+	grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+	grpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+func unaryMetrics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observeGRPCCall(info.FullMethod, start, err)
+
+	return resp, err
+}
+
+func streamMetrics(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observeGRPCCall(info.FullMethod, start, err)
+
+	return err
+}
+
+// recoverGRPCCall паника в методе -- это Internal, а не упавший сервер
+func recoverGRPCCall(ctx context.Context, logger *logrus.Logger, method string, err *error) {
+	if r := recover(); r != nil {
+		logger.WithFields(logrus.Fields{
+			"token":  requestID(ctx),
+			"method": "RECOVER",
+		}).Errorf("%s: %v\n%s", method, r, debug.Stack())
+		*err = status.Error(codes.Internal, utils.ErrInternal.Error())
+	}
+}
+
+// unaryRecover ловит паники и отдаёт Internal
+func unaryRecover(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer recoverGRPCCall(ctx, logger, info.FullMethod, &err)
+
+		return handler(ctx, req)
+	}
+}
+
+func streamRecover(logger *logrus.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) (err error) {
+		defer recoverGRPCCall(ss.Context(), logger, info.FullMethod, &err)
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pkg/errors"
+)
+
+func TestChainUnaryInterceptors(t *testing.T) {
+	calls := make([]string, 0)
+	named := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+			handler grpc.UnaryHandler) (interface{}, error) {
+			calls = append(calls, name)
+			return handler(ctx, req)
+		}
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls = append(calls, "handler")
+		return req, nil
+	}
+
+	chain := chainUnaryInterceptors(named("first"), named("second"))
+	resp, err := chain(context.Background(), "req", &grpc.UnaryServerInfo{}, handler)
+	if err != nil || resp != "req" {
+		t.Errorf("TestChainUnaryInterceptors got %v, %v", resp, err)
+	}
+
+	expected := []string{"first", "second", "handler"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("TestChainUnaryInterceptors got calls %v, expected %v", calls, expected)
+	}
+}
+
+func TestUnaryRecover(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("oops")
+	}
+
+	_, err := unaryRecover(newTestLogger())(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/models.Games/GetGameBySlug"}, handler)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("TestUnaryRecover got %v, expected Internal", err)
+	}
+}
+
+func TestWithRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestIDHeader, "abcd1234"))
+	ctx, id := withRequestID(ctx)
+	if id != "abcd1234" || requestID(ctx) != "abcd1234" {
+		t.Errorf("TestWithRequestID got %s, expected abcd1234", id)
+	}
+
+	md, _ := metadata.FromOutgoingContext(outgoingRequestID(ctx))
+	if values := md.Get(requestIDHeader); len(values) != 1 || values[0] != "abcd1234" {
+		t.Errorf("TestWithRequestID got outgoing %v, expected abcd1234", values)
+	}
+
+	ctx, id = withRequestID(context.Background())
+	if len(id) != 8 || requestID(ctx) != id {
+		t.Errorf("TestWithRequestID got generated %q", id)
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	srv := newTestServer(&gameTest{})
+	const method = "/models.Games/TestUnaryInterceptor"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	chain := srv.unaryInterceptor()
+
+	var gotID string
+	notFound := func(ctx context.Context, req interface{}) (interface{}, error) {
+		gotID = requestID(ctx)
+		return nil, errors.Wrap(utils.ErrNotExists, "can not get game by slug")
+	}
+	_, err := chain(context.Background(), &models.GameSlug{Slug: "pong"}, info, notFound)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("TestUnaryInterceptor got %v, expected NotFound", err)
+	}
+	if gotID == "" {
+		t.Errorf("TestUnaryInterceptor handler got no request id")
+	}
+
+	panics := func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("oops")
+	}
+	_, err = chain(context.Background(), &models.GameSlug{Slug: "pong"}, info, panics)
+	if status.Code(err) != codes.Internal {
+		t.Errorf("TestUnaryInterceptor got %v, expected Internal", err)
+	}
+
+	if got := testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.NotFound.String())); got != 1 {
+		t.Errorf("TestUnaryInterceptor got %v NotFound requests, expected 1", got)
+	}
+	if got := testutil.ToFloat64(grpcRequests.WithLabelValues(method, codes.Internal.String())); got != 1 {
+		t.Errorf("TestUnaryInterceptor got %v Internal requests, expected 1", got)
+	}
+}
@@ -5,15 +5,15 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/HotCodeGroup/warscript-games/gmodels"
 	"github.com/HotCodeGroup/warscript-utils/models"
 	"github.com/HotCodeGroup/warscript-utils/utils"
 	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestGetGameBySlug(t *testing.T) {
-	m := &GamesManager{}
-
-	Games = &gameTest{
+	games := &gameTest{
 		games: map[string]*GameModel{
 			"pong": {
 				ID:          1,
@@ -26,6 +26,7 @@ func TestGetGameBySlug(t *testing.T) {
 			},
 		},
 	}
+	m := newTestServer(games).GamesManager()
 
 	cases := []struct {
 		slug          string
@@ -60,3 +61,159 @@ func TestGetGameBySlug(t *testing.T) {
 		}
 	}
 }
+
+func TestGetGlobalLeaderboardGRPC(t *testing.T) {
+	games := &gameTest{}
+	m := newTestServer(games).GamesManager()
+
+	resp, err := m.GetGlobalLeaderboard(context.Background(), &gmodels.GlobalLeaderboardRequest{
+		Limit: 2,
+	})
+	if err != nil {
+		t.Fatalf("GetGlobalLeaderboard got unexpected error: %v", err)
+	}
+
+	expected := []*gmodels.GlobalLeader{
+		{
+			ID:          1,
+			Username:    "GDVFox",
+			PhotoUUID:   "2eb4a823-3a6d-4cba-8767-4d4946890f4f",
+			Rating:      2,
+			Rank:        1,
+			GamesPlayed: 2,
+		},
+		{
+			ID:          2,
+			Username:    "GDVFox1337",
+			Rating:      0.5,
+			Rank:        2,
+			GamesPlayed: 1,
+		},
+	}
+	if !reflect.DeepEqual(resp.Leaders, expected) {
+		t.Errorf("GetGlobalLeaderboard returns: %v, wanted: %v", resp.Leaders, expected)
+	}
+
+	_, err = m.GetGlobalLeaderboard(context.Background(), &gmodels.GlobalLeaderboardRequest{
+		Formula: "elo",
+	})
+	if _, ok := errors.Cause(err).(*utils.ValidationError); !ok {
+		t.Errorf("GetGlobalLeaderboard got unexpected error: %v, expected validation error", err)
+	}
+
+	_, err = m.GetGlobalLeaderboard(context.Background(), &gmodels.GlobalLeaderboardRequest{
+		Limit:  -1,
+		Offset: -1,
+	})
+	validErr, ok := errors.Cause(err).(*utils.ValidationError)
+	if !ok || len(*validErr) != 2 || (*validErr)["limit"] != "out_of_range" {
+		t.Errorf("GetGlobalLeaderboard got unexpected error: %v, expected limit and offset out of range", err)
+	}
+}
+
+func TestGetUserGamesGRPC(t *testing.T) {
+	games := &gameTest{}
+	m := newTestServer(games).GamesManager()
+
+	resp, err := m.GetUserGames(context.Background(), &gmodels.UserGamesRequest{
+		UserID: 1,
+	})
+	if err != nil {
+		t.Fatalf("GetUserGames got unexpected error: %v", err)
+	}
+
+	expected := []*gmodels.UserGame{
+		{
+			Slug:           "pong",
+			Title:          "Pong",
+			BackgroundUUID: "2eb4a823-3a6d-5xyz-8767-4d4946890f4f",
+			Score:          1337,
+			Rank:           2,
+			Percentile:     75,
+			LastPlayed:     1558353600,
+		},
+	}
+	if !reflect.DeepEqual(resp.Games, expected) {
+		t.Errorf("GetUserGames returns: %v, wanted: %v", resp.Games, expected)
+	}
+
+	games.SetNextFail(utils.ErrInternal)
+	if _, err = m.GetUserGames(context.Background(), &gmodels.UserGamesRequest{UserID: 1}); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("GetUserGames got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	games.SetNextFail(errTimeout)
+	if _, err = m.GetUserGames(context.Background(), &gmodels.UserGamesRequest{UserID: 1}); errors.Cause(err) != errTimeout {
+		t.Errorf("GetUserGames got unexpected error: %v, expected: %v", err, errTimeout)
+	}
+}
+
+func TestGetFriendsLeaderboardGRPC(t *testing.T) {
+	games := &gameTest{
+		follows: map[int64][]int64{
+			1: {2},
+		},
+	}
+	m := newTestServer(games).GamesManager()
+
+	resp, err := m.GetFriendsLeaderboard(context.Background(), &gmodels.FriendsLeaderboardRequest{
+		Slug:       "pong",
+		UserIDs:    []int64{2, 3},
+		FollowerID: 1,
+	})
+	if err != nil {
+		t.Fatalf("GetFriendsLeaderboard got unexpected error: %v", err)
+	}
+
+	expected := []*gmodels.RankedUser{
+		{ID: 2, Username: "GDVFox", Score: 100, Rank: 1, GlobalRank: 10},
+		{ID: 3, Username: "GDVFox", Score: 99, Rank: 2, GlobalRank: 20},
+		{ID: 1, Username: "GDVFox", Score: 98, Rank: 3, GlobalRank: 30},
+	}
+	if !reflect.DeepEqual(resp.Users, expected) {
+		t.Errorf("GetFriendsLeaderboard returns: %v, wanted: %v", resp.Users, expected)
+	}
+
+	_, err = m.GetFriendsLeaderboard(context.Background(), &gmodels.FriendsLeaderboardRequest{
+		Slug: "pong",
+	})
+	if _, ok := errors.Cause(err).(*utils.ValidationError); !ok {
+		t.Errorf("GetFriendsLeaderboard got unexpected error: %v, expected validation error", err)
+	}
+}
+
+func TestUpdateScoreGRPC(t *testing.T) {
+	games := &gameTest{
+		games: map[string]*GameModel{
+			"pong": {ID: 1, Slug: "pong"},
+		},
+	}
+	m := newTestServer(games).GamesManager()
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(serviceMetadataKey, testServiceToken))
+
+	resp, err := m.UpdateScore(ctx, &gmodels.ScoreUpdate{
+		Slug:   "pong",
+		UserID: 1,
+		Delta:  15,
+		Reason: "match won",
+		Source: "warscript-bots",
+	})
+	if err != nil {
+		t.Fatalf("UpdateScore got unexpected error: %v", err)
+	}
+
+	expected := &gmodels.UpdatedScore{OldScore: 10, NewScore: 25}
+	if !reflect.DeepEqual(resp, expected) {
+		t.Errorf("UpdateScore returns: %v, wanted: %v", resp, expected)
+	}
+
+	_, err = m.UpdateScore(ctx, &gmodels.ScoreUpdate{Slug: "pong", UserID: 1})
+	if _, ok := errors.Cause(err).(*utils.ValidationError); !ok {
+		t.Errorf("UpdateScore got unexpected error: %v, expected validation error", err)
+	}
+
+	_, err = m.UpdateScore(ctx, &gmodels.ScoreUpdate{Slug: "ping", UserID: 1, Source: "test"})
+	if errors.Cause(err) != utils.ErrNotExists {
+		t.Errorf("UpdateScore got unexpected error: %v, expected: %v", err, utils.ErrNotExists)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// healthCheckTimeout сколько ждём одну проверку
+	healthCheckTimeout = 2 * time.Second
+	// healthGRPCPeriod как часто обновляем статус gRPC health сервиса
+	healthGRPCPeriod = 5 * time.Second
+
+	healthStatusOK          = "ok"
+	healthStatusDegraded    = "degraded"
+	healthStatusUnavailable = "unavailable"
+)
+
+// healthCheck проверка одной зависимости. Некритичная проверка
+// не снимает инстанс с трафика, а только помечает его degraded
+type healthCheck struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+// healthChecker проверки готовности для /readyz, gRPC health и consul
+type healthChecker struct {
+	checks []*healthCheck
+
+	mu           sync.RWMutex
+	shuttingDown bool
+}
+
+// newHealthChecker проверяет postgres и соединение с warscript-users.
+// warscript-users некритичен: без него leaderboard отдаётся без имён,
+// а снимать из-за него с трафика все инстансы хуже
+func newHealthChecker(db interface {
+	PingContext(ctx context.Context) error
+}, authConn *grpc.ClientConn) *healthChecker {
+	h := &healthChecker{}
+	h.checks = append(h.checks, &healthCheck{
+		name:     "postgres",
+		critical: true,
+		check:    db.PingContext,
+	})
+	if authConn != nil {
+		h.checks = append(h.checks, &healthCheck{
+			name:  "warscript-users",
+			check: grpcConnCheck(authConn),
+		})
+	}
+
+	return h
+}
+
+// grpcConnCheck соединение считается живым, пока grpc не сдался с подключением
+func grpcConnCheck(conn *grpc.ClientConn) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return errors.Errorf("connection is %s", state)
+		}
+
+		return nil
+	}
+}
+
+// setShuttingDown с начала остановки /readyz отвечает 503,
+// чтобы балансировщики успели убрать инстанс за drain delay
+func (h *healthChecker) setShuttingDown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.shuttingDown = true
+}
+
+func (h *healthChecker) isShuttingDown() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.shuttingDown
+}
+
+// run выполняет все проверки параллельно
+func (h *healthChecker) run(ctx context.Context) *jmodels.HealthStatus {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	results := make([]error, len(h.checks))
+	wg := &sync.WaitGroup{}
+	for i, c := range h.checks {
+		wg.Add(1)
+		go func(i int, c *healthCheck) {
+			defer wg.Done()
+			results[i] = c.check(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	status := &jmodels.HealthStatus{
+		Status: healthStatusOK,
+		Checks: make(map[string]string, len(h.checks)),
+	}
+	for i, c := range h.checks {
+		if results[i] == nil {
+			status.Checks[c.name] = healthStatusOK
+			continue
+		}
+
+		status.Checks[c.name] = results[i].Error()
+		if c.critical {
+			status.Status = healthStatusUnavailable
+		} else if status.Status == healthStatusOK {
+			status.Status = healthStatusDegraded
+		}
+	}
+	if h.isShuttingDown() {
+		status.Status = healthStatusUnavailable
+		status.Checks["shutdown"] = "in progress"
+	}
+
+	return status
+}
+
+// Healthz процесс жив и отвечает; зависимости не проверяем,
+// чтобы падение базы не приводило к рестартам контейнера
+func (h *healthChecker) Healthz(w http.ResponseWriter, r *http.Request) {
+	utils.WriteApplicationJSON(w, http.StatusOK, &jmodels.HealthStatus{
+		Status: healthStatusOK,
+	})
+}
+
+// Readyz инстанс готов принимать трафик
+func (h *healthChecker) Readyz(w http.ResponseWriter, r *http.Request) {
+	status := h.run(r.Context())
+
+	code := http.StatusOK
+	if status.Status == healthStatusUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+
+	utils.WriteApplicationJSON(w, code, status)
+}
+
+// grpcServingStatus статус для gRPC health сервиса
+func (h *healthChecker) grpcServingStatus(ctx context.Context) healthpb.HealthCheckResponse_ServingStatus {
+	if h.run(ctx).Status == healthStatusUnavailable {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+// grpcHealthServices сервисы, для которых отдаём статус: "" -- весь сервер
+var grpcHealthServices = []string{"", "models.Games", "gmodels.Leaderboards"}
+
+// runGRPCHealth обновляет статус стандартного gRPC health сервиса,
+// пока не закрыт stop
+func (h *healthChecker) runGRPCHealth(server *health.Server, stop <-chan struct{}) {
+	update := func() {
+		status := h.grpcServingStatus(context.Background())
+		for _, service := range grpcHealthServices {
+			server.SetServingStatus(service, status)
+		}
+	}
+
+	update()
+	ticker := time.NewTicker(healthGRPCPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			update()
+		case <-stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/testutils"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/pkg/errors"
+)
+
+// pingerTest postgres для проверок готовности
+type pingerTest struct {
+	err error
+}
+
+func (p *pingerTest) PingContext(ctx context.Context) error {
+	return p.err
+}
+
+func failingCheck(name string, critical bool) *healthCheck {
+	return &healthCheck{
+		name:     name,
+		critical: critical,
+		check: func(ctx context.Context) error {
+			return errors.New("connection refused")
+		},
+	}
+}
+
+func TestHealthz(t *testing.T) {
+	h := newHealthChecker(&pingerTest{err: errors.New("connection refused")}, nil)
+
+	testutils.RunAPITest(t, 0, &testutils.Case{
+		ExpectedCode: 200,
+		ExpectedBody: `{"status":"ok"}`,
+		Method:       "GET",
+		Pattern:      "/healthz",
+		Endpoint:     "/healthz",
+		Function:     h.Healthz,
+	})
+}
+
+func TestReadyz(t *testing.T) {
+	ok := newHealthChecker(&pingerTest{}, nil)
+
+	degraded := newHealthChecker(&pingerTest{}, nil)
+	degraded.checks = append(degraded.checks, failingCheck("warscript-users", false))
+
+	unavailable := newHealthChecker(&pingerTest{err: errors.New("connection refused")}, nil)
+
+	shuttingDown := newHealthChecker(&pingerTest{}, nil)
+	shuttingDown.setShuttingDown()
+
+	// ответы сравниваем разобранными: easyjson пишет map в случайном порядке
+	cases := []struct {
+		checker      *healthChecker
+		expectedCode int
+		expected     *jmodels.HealthStatus
+	}{
+		{ // Всё ок
+			checker:      ok,
+			expectedCode: http.StatusOK,
+			expected: &jmodels.HealthStatus{Status: healthStatusOK,
+				Checks: map[string]string{"postgres": "ok"}},
+		},
+		{ // Некритичная зависимость лежит, трафик принимаем
+			checker:      degraded,
+			expectedCode: http.StatusOK,
+			expected: &jmodels.HealthStatus{Status: healthStatusDegraded,
+				Checks: map[string]string{"postgres": "ok", "warscript-users": "connection refused"}},
+		},
+		{ // База лежит
+			checker:      unavailable,
+			expectedCode: http.StatusServiceUnavailable,
+			expected: &jmodels.HealthStatus{Status: healthStatusUnavailable,
+				Checks: map[string]string{"postgres": "connection refused"}},
+		},
+		{ // Останавливаемся
+			checker:      shuttingDown,
+			expectedCode: http.StatusServiceUnavailable,
+			expected: &jmodels.HealthStatus{Status: healthStatusUnavailable,
+				Checks: map[string]string{"postgres": "ok", "shutdown": "in progress"}},
+		},
+	}
+
+	for i, c := range cases {
+		rr := httptest.NewRecorder()
+		c.checker.Readyz(rr, httptest.NewRequest("GET", "/readyz", nil))
+		if rr.Code != c.expectedCode {
+			t.Errorf("[%d] TestReadyz got code %d, expected %d", i, rr.Code, c.expectedCode)
+		}
+
+		got := &jmodels.HealthStatus{}
+		if err := got.UnmarshalJSON(rr.Body.Bytes()); err != nil {
+			t.Fatalf("[%d] TestReadyz got unexpected error: %s", i, err)
+		}
+		if !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("[%d] TestReadyz got %+v, expected %+v", i, got, c.expected)
+		}
+	}
+}
+
+func TestGRPCServingStatus(t *testing.T) {
+	cases := []struct {
+		checker  *healthChecker
+		expected healthpb.HealthCheckResponse_ServingStatus
+	}{
+		{checker: newHealthChecker(&pingerTest{}, nil), expected: healthpb.HealthCheckResponse_SERVING},
+		{checker: newHealthChecker(&pingerTest{err: errors.New("connection refused")}, nil),
+			expected: healthpb.HealthCheckResponse_NOT_SERVING},
+	}
+
+	for i, c := range cases {
+		if status := c.checker.grpcServingStatus(context.Background()); status != c.expected {
+			t.Errorf("[%d] TestGRPCServingStatus got %s, expected %s", i, status, c.expected)
+		}
+	}
+}
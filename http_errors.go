@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// errInvalidParams сообщение ответа 400, детали -- в полях
+	errInvalidParams = errors.New("invalid_params")
+	// errNotFound нет такого роута
+	errNotFound = errors.New("not_found")
+	// errMethodNotAllowed роут есть, а метода на нём нет
+	errMethodNotAllowed = errors.New("method_not_allowed")
+)
+
+// errorWriter пишет ошибку в лог и в ответ в формате jmodels.APIError.
+// В отличие от utils.ErrorResponseWriter ошибки валидации отдаются в том же
+// формате, что и остальные, а не голым объектом поле -> ошибка
+type errorWriter struct {
+	w      http.ResponseWriter
+	logger *logrus.Entry
+}
+
+func newErrorWriter(w http.ResponseWriter, logger *logrus.Entry) *errorWriter {
+	return &errorWriter{
+		w:      w,
+		logger: logger,
+	}
+}
+
+// WriteError запись ошибки в лог и в ответ
+func (e *errorWriter) WriteError(code int, err error) {
+	e.logger.Error(errors.Wrapf(err, "HTTP %s[%d]", http.StatusText(code), code))
+	utils.WriteApplicationJSON(e.w, code, &jmodels.APIError{Message: err.Error()})
+}
+
+// WriteWarn запись ворнинга в лог и ошибки в ответ
+func (e *errorWriter) WriteWarn(code int, err error) {
+	e.logger.Warn(errors.Wrapf(err, "HTTP %s[%d]", http.StatusText(code), code))
+	utils.WriteApplicationJSON(e.w, code, &jmodels.APIError{Message: err.Error()})
+}
+
+// WriteCause ошибка целиком -- в лог, а в ответ только её причина,
+// чтобы подробности из других сервисов не уходили клиенту
+func (e *errorWriter) WriteCause(code int, err error) {
+	logErr := errors.Wrapf(err, "HTTP %s[%d]", http.StatusText(code), code)
+	if code >= http.StatusInternalServerError {
+		e.logger.Error(logErr)
+	} else {
+		e.logger.Warn(logErr)
+	}
+	utils.WriteApplicationJSON(e.w, code, &jmodels.APIError{Message: errors.Cause(err).Error()})
+}
+
+// WriteValidationError 400 с ошибками по полям
+func (e *errorWriter) WriteValidationError(validErr *utils.ValidationError) {
+	e.logger.Warn(errors.Wrapf(validErr, "HTTP %s[%d]", http.StatusText(http.StatusBadRequest), http.StatusBadRequest))
+	utils.WriteApplicationJSON(e.w, http.StatusBadRequest, validationErrorToJSON(validErr))
+}
+
+// validationErrorToJSON поля в стабильном порядке, как и в BadRequest у gRPC
+func validationErrorToJSON(validErr *utils.ValidationError) *jmodels.APIError {
+	fields := make([]string, 0, len(*validErr))
+	for field := range *validErr {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	resp := &jmodels.APIError{
+		Message: errInvalidParams.Error(),
+		Fields:  make([]*jmodels.FieldError, len(fields)),
+	}
+	for i, field := range fields {
+		resp.Fields[i] = &jmodels.FieldError{
+			Field: field,
+			Error: (*validErr)[field],
+		}
+	}
+
+	return resp
+}
+
+// writeInternalError 504, если не уложились в таймаут, иначе 500
+func writeInternalError(errWriter *errorWriter, err error) {
+	if errors.Cause(err) == errTimeout {
+		errWriter.WriteError(http.StatusGatewayTimeout, err)
+		return
+	}
+
+	errWriter.WriteError(http.StatusInternalServerError, err)
+}
+
+// notFoundHandler 404 неизвестного роута в том же формате, что и остальные ошибки
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	utils.WriteApplicationJSON(w, http.StatusNotFound, &jmodels.APIError{Message: errNotFound.Error()})
+}
+
+// methodNotAllowedHandler 405 в том же формате
+func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	utils.WriteApplicationJSON(w, http.StatusMethodNotAllowed, &jmodels.APIError{Message: errMethodNotAllowed.Error()})
+}
@@ -8,15 +8,36 @@ import (
 	"github.com/HotCodeGroup/warscript-utils/logging"
 	"github.com/HotCodeGroup/warscript-utils/testutils"
 	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/sirupsen/logrus"
 )
 
-func init() {
-	// выключаем логгер
-	logger, _ = logging.NewLogger(ioutil.Discard, "")
+// newTestLogger выключенный логгер
+func newTestLogger() *logrus.Logger {
+	logger, _ := logging.NewLogger(ioutil.Discard, "")
+	return logger
 }
 
-func initTests() {
-	Games = &gameTest{
+// сессии тестового сервера: admin -- пользователь 1, player -- 2;
+// testServiceToken -- токен сервиса для gRPC UpdateScore
+const (
+	testAdminToken   = "admin-session"
+	testPlayerToken  = "player-session"
+	testServiceToken = "bots-token"
+)
+
+// newTestServer сервис поверх games без внешних зависимостей
+func newTestServer(games GameAccessObject) *Server {
+	auth := &sessionAuthClient{sessions: map[string]int64{
+		testAdminToken:  1,
+		testPlayerToken: 2,
+	}}
+
+	return NewServer(&Config{AdminIDs: []int64{1}, ServiceTokens: []string{testServiceToken}},
+		games, auth, newTestLogger())
+}
+
+func initTests() *Server {
+	return newTestServer(&gameTest{
 		games: map[string]*GameModel{
 			"pong": {
 				ID:             1,
@@ -30,7 +51,7 @@ func initTests() {
 				BackgroundUUID: sql.NullString{String: "2eb4a823-3a6d-5xyz-8767-4d4946890f4f", Valid: true},
 			},
 		},
-	}
+	})
 }
 
 type GameTestCase struct {
@@ -38,35 +59,35 @@ type GameTestCase struct {
 	Failure error
 }
 
-func runTableAPITests(t *testing.T, cases []*GameTestCase) {
+func runTableAPITests(t *testing.T, srv *Server, cases []*GameTestCase) {
 	for i, c := range cases {
-		runAPITest(t, i, c)
+		runAPITest(t, srv, i, c)
 	}
 }
 
-func runAPITest(t *testing.T, i int, c *GameTestCase) {
+func runAPITest(t *testing.T, srv *Server, i int, c *GameTestCase) {
 	if c.Failure != nil {
-		Games.(*gameTest).SetNextFail(c.Failure)
+		srv.games.(*gameTest).SetNextFail(c.Failure)
 	}
 
 	testutils.RunAPITest(t, i, &c.Case)
 }
 
 func TestGetGame(t *testing.T) {
-	initTests()
+	srv := initTests()
 
 	cases := []*GameTestCase{
 		{ // Всё ок
 			Case: testutils.Case{
 				ExpectedCode: 200,
-				ExpectedBody: `{"slug":"pong","title":"Pong","background_uuid":"2eb4a823-3a6d-5xyz-8767-4d4946890f4f",` +
-					`"description":"Very cool game(net)","rules":"Do not cheat, please",` +
+				ExpectedBody: `{"description":"Very cool game(net)","rules":"Do not cheat, please",` +
 					`"code_example":"const a = 5;","bot_code":"const a = 5;",` +
-					`"logo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f"}`,
+					`"logo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f",` +
+					`"slug":"pong","title":"Pong","background_uuid":"2eb4a823-3a6d-5xyz-8767-4d4946890f4f"}`,
 				Method:   "GET",
 				Pattern:  "/games/{game_slug}",
 				Endpoint: "/games/pong",
-				Function: GetGame,
+				Function: srv.GetGame,
 			},
 		},
 		{ // Такой игрули нет
@@ -76,7 +97,7 @@ func TestGetGame(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}",
 				Endpoint:     "/games/not_pong",
-				Function:     GetGame,
+				Function:     srv.GetGame,
 			},
 			Failure: utils.ErrNotExists,
 		},
@@ -87,17 +108,28 @@ func TestGetGame(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}",
 				Endpoint:     "/games/not_pong",
-				Function:     GetGame,
+				Function:     srv.GetGame,
 			},
 			Failure: utils.ErrInternal,
 		},
+		{ // база не ответила за QueryTimeout
+			Case: testutils.Case{
+				ExpectedCode: 504,
+				ExpectedBody: `{"message":"get game method error: deadline_exceeded"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}",
+				Endpoint:     "/games/pong",
+				Function:     srv.GetGame,
+			},
+			Failure: errTimeout,
+		},
 	}
 
-	runTableAPITests(t, cases)
+	runTableAPITests(t, srv, cases)
 }
 
 func TestGetGameList(t *testing.T) {
-	initTests()
+	srv := initTests()
 
 	cases := []*GameTestCase{
 		{ // Всё ок
@@ -107,7 +139,7 @@ func TestGetGameList(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games",
 				Endpoint:     "/games",
-				Function:     GetGameList,
+				Function:     srv.GetGameList,
 			},
 		},
 		{ // база сломалась
@@ -117,28 +149,28 @@ func TestGetGameList(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games",
 				Endpoint:     "/games",
-				Function:     GetGameList,
+				Function:     srv.GetGameList,
 			},
 			Failure: utils.ErrInternal,
 		},
 	}
 
-	runTableAPITests(t, cases)
+	runTableAPITests(t, srv, cases)
 }
 
 func TestGetGameLeaderboard(t *testing.T) {
-	initTests()
+	srv := initTests()
 
 	cases := []*GameTestCase{
 		{ // Всё ок
 			Case: testutils.Case{
 				ExpectedCode: 200,
-				ExpectedBody: `[{"username":"GDVFox","photo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f","id":1,"active":false,"score":1337},` +
-					`{"username":"GDVFox1337","photo_uuid":"","id":2,"active":false,"score":1337}]`,
+				ExpectedBody: `[{"score":1337,"id":1,"active":false,"username":"GDVFox","photo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f"},` +
+					`{"score":1337,"id":2,"active":false,"username":"GDVFox1337","photo_uuid":""}]`,
 				Method:   "GET",
 				Pattern:  "/games/{game_slug}/leaderboard",
 				Endpoint: "/games/pong/leaderboard",
-				Function: GetGameLeaderboard,
+				Function: srv.GetGameLeaderboard,
 			},
 		},
 		{ // Такой игрули нет
@@ -148,7 +180,7 @@ func TestGetGameLeaderboard(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}/leaderboard",
 				Endpoint:     "/games/pong/leaderboard",
-				Function:     GetGameLeaderboard,
+				Function:     srv.GetGameLeaderboard,
 			},
 			Failure: utils.ErrNotExists,
 		},
@@ -159,17 +191,48 @@ func TestGetGameLeaderboard(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}/leaderboard",
 				Endpoint:     "/games/pong/leaderboard",
-				Function:     GetGameLeaderboard,
+				Function:     srv.GetGameLeaderboard,
 			},
 			Failure: utils.ErrInternal,
 		},
+		{ // limit не число
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"limit","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?limit=five",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // слишком большой limit и отрицательный offset
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"limit","error":"out_of_range"},` +
+					`{"field":"offset","error":"out_of_range"}]}`,
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard",
+				Endpoint: "/games/pong/leaderboard?limit=100000&offset=-1",
+				Function: srv.GetGameLeaderboard,
+			},
+		},
+		{ // границы тоже проверяются до похода в базу с окном
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"limit","error":"out_of_range"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=day&limit=0",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
 	}
 
-	runTableAPITests(t, cases)
+	runTableAPITests(t, srv, cases)
 }
 
 func TestGetGameTotalPlayers(t *testing.T) {
-	initTests()
+	srv := initTests()
 
 	cases := []*GameTestCase{
 		{ // Всё ок
@@ -179,7 +242,7 @@ func TestGetGameTotalPlayers(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}/leaderboard/count",
 				Endpoint:     "/games/pong/leaderboard/count",
-				Function:     GetGameTotalPlayers,
+				Function:     srv.GetGameTotalPlayers,
 			},
 		},
 		{ // Такой игрули нет
@@ -189,7 +252,7 @@ func TestGetGameTotalPlayers(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}/leaderboard/count",
 				Endpoint:     "/games/pong/leaderboard/count",
-				Function:     GetGameTotalPlayers,
+				Function:     srv.GetGameTotalPlayers,
 			},
 			Failure: utils.ErrNotExists,
 		},
@@ -200,11 +263,509 @@ func TestGetGameTotalPlayers(t *testing.T) {
 				Method:       "GET",
 				Pattern:      "/games/{game_slug}/leaderboard/count",
 				Endpoint:     "/games/pong/leaderboard/count",
-				Function:     GetGameTotalPlayers,
+				Function:     srv.GetGameTotalPlayers,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetGameScoreStats(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // Такой игрули нет
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"game not exists: not_exists"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard/stats",
+				Endpoint:     "/games/not_pong/leaderboard/stats",
+				Function:     srv.GetGameScoreStats,
+			},
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"get game score stats method error: internal server error"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard/stats",
+				Endpoint:     "/games/pong/leaderboard/stats",
+				Function:     srv.GetGameScoreStats,
+			},
+			Failure: utils.ErrInternal,
+		},
+		{ // кривое количество столбцов
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"buckets","error":"out_of_range"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard/stats",
+				Endpoint:     "/games/pong/leaderboard/stats?buckets=1000",
+				Function:     srv.GetGameScoreStats,
+			},
+		},
+		{ // Всё ок
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `{"count":3,"min":0,"max":9,"p50":5,"p90":9,"p99":9,` +
+					`"buckets":[{"from":0,"to":5,"count":1},{"from":5,"to":10,"count":2}]}`,
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard/stats",
+				Endpoint: "/games/pong/leaderboard/stats?buckets=2",
+				Function: srv.GetGameScoreStats,
+			},
+		},
+		{ // база сломалась, но ответ уже в кэше
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `{"count":3,"min":0,"max":9,"p50":5,"p90":9,"p99":9,` +
+					`"buckets":[{"from":0,"to":5,"count":1},{"from":5,"to":10,"count":2}]}`,
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard/stats",
+				Endpoint: "/games/pong/leaderboard/stats?buckets=2",
+				Function: srv.GetGameScoreStats,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetGlobalLeaderboard(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // Всё ок
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"rating":2,"rank":1,"games_played":2,"id":1,"active":false,` +
+					`"username":"GDVFox","photo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f"},` +
+					`{"rating":0.5,"rank":2,"games_played":1,"id":2,"active":false,` +
+					`"username":"GDVFox1337","photo_uuid":""}]`,
+				Method:   "GET",
+				Pattern:  "/leaderboard",
+				Endpoint: "/leaderboard?formula=scores",
+				Function: srv.GetGlobalLeaderboard,
+			},
+		},
+		{ // offset не число
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"offset","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/leaderboard",
+				Endpoint:     "/leaderboard?offset=99999999999999999999",
+				Function:     srv.GetGlobalLeaderboard,
+			},
+		},
+		{ // нет такой формулы
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"formula","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/leaderboard",
+				Endpoint:     "/leaderboard?formula=elo",
+				Function:     srv.GetGlobalLeaderboard,
+			},
+		},
+		{ // никто ещё не играл
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"no players or offset is large: not_exists"}`,
+				Method:       "GET",
+				Pattern:      "/leaderboard",
+				Endpoint:     "/leaderboard",
+				Function:     srv.GetGlobalLeaderboard,
+			},
+			Failure: utils.ErrNotExists,
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"get global leaderboard method error: internal server error"}`,
+				Method:       "GET",
+				Pattern:      "/leaderboard",
+				Endpoint:     "/leaderboard",
+				Function:     srv.GetGlobalLeaderboard,
 			},
 			Failure: utils.ErrInternal,
 		},
 	}
 
-	runTableAPITests(t, cases)
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetUserGames(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // Всё ок
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"score":1337,"rank":2,"percentile":75,"last_played":"2019-05-20T12:00:00Z",` +
+					`"slug":"pong","title":"Pong","background_uuid":"2eb4a823-3a6d-5xyz-8767-4d4946890f4f"}]`,
+				Method:   "GET",
+				Pattern:  "/users/{user_id}/games",
+				Endpoint: "/users/1/games",
+				Function: srv.GetUserGames,
+			},
+		},
+		{ // ни во что не играл
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[]`,
+				Method:       "GET",
+				Pattern:      "/users/{user_id}/games",
+				Endpoint:     "/users/2/games",
+				Function:     srv.GetUserGames,
+			},
+		},
+		{ // кривой id
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"user_id","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/users/{user_id}/games",
+				Endpoint:     "/users/kek/games",
+				Function:     srv.GetUserGames,
+			},
+		},
+		{ // id должен быть положительным
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"user_id","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/users/{user_id}/games",
+				Endpoint:     "/users/-3/games",
+				Function:     srv.GetUserGames,
+			},
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"get user games method error: internal server error"}`,
+				Method:       "GET",
+				Pattern:      "/users/{user_id}/games",
+				Endpoint:     "/users/1/games",
+				Function:     srv.GetUserGames,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetGameLeaderboardForUsers(t *testing.T) {
+	srv := initTests()
+	srv.games.(*gameTest).follows = map[int64][]int64{
+		1: {2, 3},
+	}
+
+	cases := []*GameTestCase{
+		{ // явный список
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"rank":1,"global_rank":10,"score":100,"id":5,"active":false,"username":"GDVFox","photo_uuid":""},` +
+					`{"rank":2,"global_rank":20,"score":99,"id":7,"active":false,"username":"GDVFox","photo_uuid":""}]`,
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard",
+				Endpoint: "/games/pong/leaderboard?users=5,7,5",
+				Function: srv.GetGameLeaderboard,
+			},
+		},
+		{ // подписки вместе с самим пользователем
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"rank":1,"global_rank":10,"score":100,"id":1,"active":false,"username":"GDVFox","photo_uuid":""},` +
+					`{"rank":2,"global_rank":20,"score":99,"id":2,"active":false,"username":"GDVFox","photo_uuid":""},` +
+					`{"rank":3,"global_rank":30,"score":98,"id":3,"active":false,"username":"GDVFox","photo_uuid":""}]`,
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard",
+				Endpoint: "/games/pong/leaderboard?followed_by=1",
+				Function: srv.GetGameLeaderboard,
+			},
+		},
+		{ // кривой список
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"users","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?users=1,kek",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // кривой подписчик
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"followed_by","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?followed_by=kek",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"get game method error: internal server error"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?users=1",
+				Function:     srv.GetGameLeaderboard,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestFollowUser(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // подписались
+			Case: testutils.Case{
+				ExpectedCode: 204,
+				Method:       "PUT",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/1/following/2",
+				Function:     srv.FollowUser,
+			},
+		},
+		{ // видим подписку
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[2]`,
+				Method:       "GET",
+				Pattern:      "/users/{user_id}/following",
+				Endpoint:     "/users/1/following",
+				Function:     srv.GetFollowedUsers,
+			},
+		},
+		{ // на себя нельзя
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"followee_id","error":"invalid"}]}`,
+				Method:       "PUT",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/1/following/1",
+				Function:     srv.FollowUser,
+			},
+		},
+		{ // кривые id
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"followee_id","error":"invalid"},{"field":"user_id","error":"invalid"}]}`,
+				Method:       "PUT",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/kek/following/lol",
+				Function:     srv.FollowUser,
+			},
+		},
+		{ // отписались
+			Case: testutils.Case{
+				ExpectedCode: 204,
+				Method:       "DELETE",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/1/following/2",
+				Function:     srv.UnfollowUser,
+			},
+		},
+		{ // уже отписаны
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"follow not exists: not_exists"}`,
+				Method:       "DELETE",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/1/following/2",
+				Function:     srv.UnfollowUser,
+			},
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"follow user method error: internal server error"}`,
+				Method:       "PUT",
+				Pattern:      "/users/{user_id}/following/{followee_id}",
+				Endpoint:     "/users/1/following/2",
+				Function:     srv.FollowUser,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetGameWindowLeaderboard(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // за сутки (в фейке points -- длина окна в часах)
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"points":24,"rank":1,"id":1,"active":false,"username":"GDVFox","photo_uuid":""}]`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=day",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // за прошлый февраль
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"points":672,"rank":1,"id":1,"active":false,"username":"GDVFox","photo_uuid":""}]`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=month&at=2019-02-10",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // нет такого окна
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"window","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=year",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // кривая дата
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"at","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=week&at=yesterday",
+				Function:     srv.GetGameLeaderboard,
+			},
+		},
+		{ // никто ничего не набрал
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"game not exists or offset is large: not_exists"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard",
+				Endpoint:     "/games/pong/leaderboard?window=week",
+				Function:     srv.GetGameLeaderboard,
+			},
+			Failure: utils.ErrNotExists,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestGetUserScoreEvents(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // Всё ок
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `[{"id":2,"user_id":1,"old_score":10,"new_score":25,"reason":"match won",` +
+					`"source":"warscript-bots","match_id":"42","created_at":"2019-05-20T12:00:00Z"}]`,
+				Method:   "GET",
+				Pattern:  "/admin/games/{game_slug}/users/{user_id}/score-events",
+				Endpoint: "/admin/games/pong/users/1/score-events",
+				Function: srv.GetUserScoreEvents,
+			},
+		},
+		{ // Такой игрули нет
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"game not exists: not_exists"}`,
+				Method:       "GET",
+				Pattern:      "/admin/games/{game_slug}/users/{user_id}/score-events",
+				Endpoint:     "/admin/games/not_pong/users/1/score-events",
+				Function:     srv.GetUserScoreEvents,
+			},
+		},
+		{ // кривой id
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"user_id","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/admin/games/{game_slug}/users/{user_id}/score-events",
+				Endpoint:     "/admin/games/pong/users/kek/score-events",
+				Function:     srv.GetUserScoreEvents,
+			},
+		},
+		{ // база сломалась
+			Case: testutils.Case{
+				ExpectedCode: 500,
+				ExpectedBody: `{"message":"get score events method error: internal server error"}`,
+				Method:       "GET",
+				Pattern:      "/admin/games/{game_slug}/users/{user_id}/score-events",
+				Endpoint:     "/admin/games/pong/users/1/score-events",
+				Function:     srv.GetUserScoreEvents,
+			},
+			Failure: utils.ErrInternal,
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
+}
+
+func TestExportGameLeaderboard(t *testing.T) {
+	srv := initTests()
+
+	cases := []*GameTestCase{
+		{ // csv
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: "rank,user_id,username,score,active,photo_uuid\n" +
+					"1,1,GDVFox,1337,true,2eb4a823-3a6d-4cba-8767-4d4946890f4f\n" +
+					"1,2,\"GDV,Fox\",1337,false,\n" +
+					"3,3,newbie,0,false,\n",
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard/export",
+				Endpoint: "/games/pong/leaderboard/export?format=csv",
+				Function: srv.ExportGameLeaderboard,
+			},
+		},
+		{ // ndjson
+			Case: testutils.Case{
+				ExpectedCode: 200,
+				ExpectedBody: `{"rank":1,"global_rank":1,"score":1337,"id":1,"active":true,` +
+					`"username":"GDVFox","photo_uuid":"2eb4a823-3a6d-4cba-8767-4d4946890f4f"}` + "\n" +
+					`{"rank":1,"global_rank":1,"score":1337,"id":2,"active":false,"username":"GDV,Fox","photo_uuid":""}` + "\n" +
+					`{"rank":3,"global_rank":3,"score":0,"id":3,"active":false,"username":"newbie","photo_uuid":""}` + "\n",
+				Method:   "GET",
+				Pattern:  "/games/{game_slug}/leaderboard/export",
+				Endpoint: "/games/pong/leaderboard/export?format=ndjson",
+				Function: srv.ExportGameLeaderboard,
+			},
+		},
+		{ // нет такого формата
+			Case: testutils.Case{
+				ExpectedCode: 400,
+				ExpectedBody: `{"message":"invalid_params","fields":[{"field":"format","error":"invalid"}]}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard/export",
+				Endpoint:     "/games/pong/leaderboard/export?format=parquet",
+				Function:     srv.ExportGameLeaderboard,
+			},
+		},
+		{ // Такой игрули нет
+			Case: testutils.Case{
+				ExpectedCode: 404,
+				ExpectedBody: `{"message":"game not exists: not_exists"}`,
+				Method:       "GET",
+				Pattern:      "/games/{game_slug}/leaderboard/export",
+				Endpoint:     "/games/not_pong/leaderboard/export",
+				Function:     srv.ExportGameLeaderboard,
+			},
+		},
+	}
+
+	runTableAPITests(t, srv, cases)
 }
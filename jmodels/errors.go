@@ -0,0 +1,14 @@
+package jmodels
+
+// APIError ответ с ошибкой, одинаковый для всех эндпоинтов
+type APIError struct {
+	Message string `json:"message"`
+	// Fields ошибки по параметрам запроса, отсортированы по имени поля
+	Fields []*FieldError `json:"fields,omitempty"`
+}
+
+// FieldError ошибка в одном параметре запроса: invalid, out_of_range и т.п.
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
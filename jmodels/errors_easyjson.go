@@ -0,0 +1,228 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *FieldError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "field":
+			out.Field = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in FieldError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"field\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Field))
+	}
+	{
+		const prefix string = ",\"error\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v FieldError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v FieldError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *FieldError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *FieldError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
+func easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(in *jlexer.Lexer, out *APIError) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "message":
+			out.Message = string(in.String())
+		case "fields":
+			if in.IsNull() {
+				in.Skip()
+				out.Fields = nil
+			} else {
+				in.Delim('[')
+				if out.Fields == nil {
+					if !in.IsDelim(']') {
+						out.Fields = make([]*FieldError, 0, 8)
+					} else {
+						out.Fields = []*FieldError{}
+					}
+				} else {
+					out.Fields = (out.Fields)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 *FieldError
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						if v1 == nil {
+							v1 = new(FieldError)
+						}
+						(*v1).UnmarshalEasyJSON(in)
+					}
+					out.Fields = append(out.Fields, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(out *jwriter.Writer, in APIError) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"message\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Message))
+	}
+	if len(in.Fields) != 0 {
+		const prefix string = ",\"fields\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.Fields {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				if v3 == nil {
+					out.RawString("null")
+				} else {
+					(*v3).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v APIError) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v APIError) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD31a5a85EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *APIError) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *APIError) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD31a5a85DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(l, v)
+}
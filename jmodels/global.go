@@ -0,0 +1,9 @@
+package jmodels
+
+// GlobalLeader игрок в общем рейтинге по всем играм
+type GlobalLeader struct {
+	InfoUser
+	Rating      float64 `json:"rating"`
+	Rank        int64   `json:"rank"`
+	GamesPlayed int32   `json:"games_played"`
+}
@@ -0,0 +1,163 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson76e781b5DecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *GlobalLeader) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rating":
+			out.Rating = float64(in.Float64())
+		case "rank":
+			out.Rank = int64(in.Int64())
+		case "games_played":
+			out.GamesPlayed = int32(in.Int32())
+		case "id":
+			out.ID = int64(in.Int64())
+		case "active":
+			out.Active = bool(in.Bool())
+		case "username":
+			out.Username = string(in.String())
+		case "photo_uuid":
+			out.PhotoUUID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson76e781b5EncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in GlobalLeader) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rating\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.Rating))
+	}
+	{
+		const prefix string = ",\"rank\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Rank))
+	}
+	{
+		const prefix string = ",\"games_played\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int32(int32(in.GamesPlayed))
+	}
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"active\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Active))
+	}
+	{
+		const prefix string = ",\"username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"photo_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.PhotoUUID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GlobalLeader) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson76e781b5EncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GlobalLeader) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson76e781b5EncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GlobalLeader) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson76e781b5DecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GlobalLeader) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson76e781b5DecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
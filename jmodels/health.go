@@ -0,0 +1,7 @@
+package jmodels
+
+// HealthStatus ответ /healthz и /readyz
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
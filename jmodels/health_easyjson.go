@@ -0,0 +1,135 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson53c2c5caDecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *HealthStatus) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		case "checks":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				if !in.IsDelim('}') {
+					out.Checks = make(map[string]string)
+				} else {
+					out.Checks = nil
+				}
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 string
+					v1 = string(in.String())
+					(out.Checks)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson53c2c5caEncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in HealthStatus) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Status))
+	}
+	if len(in.Checks) != 0 {
+		const prefix string = ",\"checks\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		{
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.Checks {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				out.String(string(v2Value))
+			}
+			out.RawByte('}')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HealthStatus) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson53c2c5caEncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HealthStatus) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson53c2c5caEncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HealthStatus) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson53c2c5caDecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HealthStatus) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson53c2c5caDecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
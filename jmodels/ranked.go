@@ -0,0 +1,9 @@
+package jmodels
+
+// RankedUser ScoredUser с местом внутри выбранной группы игроков
+// и местом среди всех игроков игры
+type RankedUser struct {
+	ScoredUser
+	Rank       int64 `json:"rank"`
+	GlobalRank int64 `json:"global_rank"`
+}
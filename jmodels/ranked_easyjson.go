@@ -0,0 +1,163 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson36056635DecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *RankedUser) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "rank":
+			out.Rank = int64(in.Int64())
+		case "global_rank":
+			out.GlobalRank = int64(in.Int64())
+		case "score":
+			out.Score = int32(in.Int32())
+		case "id":
+			out.ID = int64(in.Int64())
+		case "active":
+			out.Active = bool(in.Bool())
+		case "username":
+			out.Username = string(in.String())
+		case "photo_uuid":
+			out.PhotoUUID = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson36056635EncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in RankedUser) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"rank\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Rank))
+	}
+	{
+		const prefix string = ",\"global_rank\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.GlobalRank))
+	}
+	{
+		const prefix string = ",\"score\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int32(int32(in.Score))
+	}
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"active\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Bool(bool(in.Active))
+	}
+	{
+		const prefix string = ",\"username\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"photo_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.PhotoUUID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v RankedUser) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson36056635EncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v RankedUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson36056635EncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *RankedUser) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson36056635DecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *RankedUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson36056635DecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
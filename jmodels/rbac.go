@@ -0,0 +1,24 @@
+package jmodels
+
+import "time"
+
+// Grant роль пользователя: на одну игру или, без game_slug, на все
+type Grant struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	GameSlug  string    `json:"game_slug,omitempty"`
+	Role      string    `json:"role"`
+	GrantedBy int64     `json:"granted_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GameUpdate изменение игры: поля, которых нет в запросе, остаются как были
+type GameUpdate struct {
+	Title          *string `json:"title,omitempty"`
+	Description    *string `json:"description,omitempty"`
+	Rules          *string `json:"rules,omitempty"`
+	CodeExample    *string `json:"code_example,omitempty"`
+	BotCode        *string `json:"bot_code,omitempty"`
+	LogoUUID       *string `json:"logo_uuid,omitempty"`
+	BackgroundUUID *string `json:"background_uuid,omitempty"`
+}
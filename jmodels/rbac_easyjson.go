@@ -0,0 +1,352 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *Grant) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "game_slug":
+			out.GameSlug = string(in.String())
+		case "role":
+			out.Role = string(in.String())
+		case "granted_by":
+			out.GrantedBy = int64(in.Int64())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in Grant) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.UserID))
+	}
+	if in.GameSlug != "" {
+		const prefix string = ",\"game_slug\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.GameSlug))
+	}
+	{
+		const prefix string = ",\"role\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Role))
+	}
+	{
+		const prefix string = ",\"granted_by\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.GrantedBy))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Grant) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Grant) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Grant) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Grant) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
+func easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(in *jlexer.Lexer, out *GameUpdate) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "title":
+			if in.IsNull() {
+				in.Skip()
+				out.Title = nil
+			} else {
+				if out.Title == nil {
+					out.Title = new(string)
+				}
+				*out.Title = string(in.String())
+			}
+		case "description":
+			if in.IsNull() {
+				in.Skip()
+				out.Description = nil
+			} else {
+				if out.Description == nil {
+					out.Description = new(string)
+				}
+				*out.Description = string(in.String())
+			}
+		case "rules":
+			if in.IsNull() {
+				in.Skip()
+				out.Rules = nil
+			} else {
+				if out.Rules == nil {
+					out.Rules = new(string)
+				}
+				*out.Rules = string(in.String())
+			}
+		case "code_example":
+			if in.IsNull() {
+				in.Skip()
+				out.CodeExample = nil
+			} else {
+				if out.CodeExample == nil {
+					out.CodeExample = new(string)
+				}
+				*out.CodeExample = string(in.String())
+			}
+		case "bot_code":
+			if in.IsNull() {
+				in.Skip()
+				out.BotCode = nil
+			} else {
+				if out.BotCode == nil {
+					out.BotCode = new(string)
+				}
+				*out.BotCode = string(in.String())
+			}
+		case "logo_uuid":
+			if in.IsNull() {
+				in.Skip()
+				out.LogoUUID = nil
+			} else {
+				if out.LogoUUID == nil {
+					out.LogoUUID = new(string)
+				}
+				*out.LogoUUID = string(in.String())
+			}
+		case "background_uuid":
+			if in.IsNull() {
+				in.Skip()
+				out.BackgroundUUID = nil
+			} else {
+				if out.BackgroundUUID == nil {
+					out.BackgroundUUID = new(string)
+				}
+				*out.BackgroundUUID = string(in.String())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(out *jwriter.Writer, in GameUpdate) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	if in.Title != nil {
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Title))
+	}
+	if in.Description != nil {
+		const prefix string = ",\"description\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Description))
+	}
+	if in.Rules != nil {
+		const prefix string = ",\"rules\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.Rules))
+	}
+	if in.CodeExample != nil {
+		const prefix string = ",\"code_example\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.CodeExample))
+	}
+	if in.BotCode != nil {
+		const prefix string = ",\"bot_code\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.BotCode))
+	}
+	if in.LogoUUID != nil {
+		const prefix string = ",\"logo_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.LogoUUID))
+	}
+	if in.BackgroundUUID != nil {
+		const prefix string = ",\"background_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(*in.BackgroundUUID))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v GameUpdate) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v GameUpdate) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonF2e9754EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *GameUpdate) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *GameUpdate) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonF2e9754DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(l, v)
+}
@@ -0,0 +1,15 @@
+package jmodels
+
+import "time"
+
+// ScoreEvent запись истории очков пользователя в игре
+type ScoreEvent struct {
+	ID        int64     `json:"id"`
+	UserID    int64     `json:"user_id"`
+	OldScore  int32     `json:"old_score"`
+	NewScore  int32     `json:"new_score"`
+	Reason    string    `json:"reason"`
+	Source    string    `json:"source"`
+	MatchID   string    `json:"match_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
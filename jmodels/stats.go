@@ -0,0 +1,19 @@
+package jmodels
+
+// ScoreBucket один столбец гистограммы очков: [from, to)
+type ScoreBucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int64   `json:"count"`
+}
+
+// ScoreStats распределение очков игроков в игре
+type ScoreStats struct {
+	Count   int64          `json:"count"`
+	Min     int32          `json:"min"`
+	Max     int32          `json:"max"`
+	P50     float64        `json:"p50"`
+	P90     float64        `json:"p90"`
+	P99     float64        `json:"p99"`
+	Buckets []*ScoreBucket `json:"buckets"`
+}
@@ -0,0 +1,302 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package jmodels
+
+import (
+	json "encoding/json"
+
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *ScoreStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "count":
+			out.Count = int64(in.Int64())
+		case "min":
+			out.Min = int32(in.Int32())
+		case "max":
+			out.Max = int32(in.Int32())
+		case "p50":
+			out.P50 = float64(in.Float64())
+		case "p90":
+			out.P90 = float64(in.Float64())
+		case "p99":
+			out.P99 = float64(in.Float64())
+		case "buckets":
+			if in.IsNull() {
+				in.Skip()
+				out.Buckets = nil
+			} else {
+				in.Delim('[')
+				if out.Buckets == nil {
+					if !in.IsDelim(']') {
+						out.Buckets = make([]*ScoreBucket, 0, 8)
+					} else {
+						out.Buckets = []*ScoreBucket{}
+					}
+				} else {
+					out.Buckets = (out.Buckets)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 *ScoreBucket
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						if v1 == nil {
+							v1 = new(ScoreBucket)
+						}
+						(*v1).UnmarshalEasyJSON(in)
+					}
+					out.Buckets = append(out.Buckets, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in ScoreStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"count\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Count))
+	}
+	{
+		const prefix string = ",\"min\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int32(int32(in.Min))
+	}
+	{
+		const prefix string = ",\"max\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int32(int32(in.Max))
+	}
+	{
+		const prefix string = ",\"p50\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.P50))
+	}
+	{
+		const prefix string = ",\"p90\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.P90))
+	}
+	{
+		const prefix string = ",\"p99\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.P99))
+	}
+	{
+		const prefix string = ",\"buckets\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		if in.Buckets == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Buckets {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				if v3 == nil {
+					out.RawString("null")
+				} else {
+					(*v3).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScoreStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScoreStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScoreStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScoreStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(in *jlexer.Lexer, out *ScoreBucket) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "from":
+			out.From = float64(in.Float64())
+		case "to":
+			out.To = float64(in.Float64())
+		case "count":
+			out.Count = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(out *jwriter.Writer, in ScoreBucket) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"from\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.From))
+	}
+	{
+		const prefix string = ",\"to\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Float64(float64(in.To))
+	}
+	{
+		const prefix string = ",\"count\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.Int64(int64(in.Count))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScoreBucket) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScoreBucket) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComHotCodeGroupWarscriptGamesJmodels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScoreBucket) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScoreBucket) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComHotCodeGroupWarscriptGamesJmodels1(l, v)
+}
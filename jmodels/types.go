@@ -35,3 +35,8 @@ type GameFull struct {
 	BotCode     string `json:"bot_code"`
 	LogoUUID    string `json:"logo_uuid"`
 }
+
+// PlayersCount количество игроков в игре
+type PlayersCount struct {
+	Count int64 `json:"count"`
+}
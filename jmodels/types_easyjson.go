@@ -18,7 +18,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjson6601e8cdDecodeJsongen(in *jlexer.Lexer, out *GameFull) {
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels(in *jlexer.Lexer, out *ScoredUser) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -37,22 +37,16 @@ func easyjson6601e8cdDecodeJsongen(in *jlexer.Lexer, out *GameFull) {
 			continue
 		}
 		switch key {
-		case "description":
-			out.Description = string(in.String())
-		case "rules":
-			out.Rules = string(in.String())
-		case "code_example":
-			out.CodeExample = string(in.String())
-		case "bot_code":
-			out.BotCode = string(in.String())
-		case "logo_uuid":
-			out.LogoUUID = string(in.String())
-		case "slug":
-			out.Slug = string(in.String())
-		case "title":
-			out.Title = string(in.String())
-		case "background_uuid":
-			out.BackgroundUUID = string(in.String())
+		case "score":
+			out.Score = int32(in.Int32())
+		case "id":
+			out.ID = int64(in.Int64())
+		case "active":
+			out.Active = bool(in.Bool())
+		case "username":
+			out.Username = string(in.String())
+		case "photo_uuid":
+			out.PhotoUUID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -63,117 +57,158 @@ func easyjson6601e8cdDecodeJsongen(in *jlexer.Lexer, out *GameFull) {
 		in.Consumed()
 	}
 }
-func easyjson6601e8cdEncodeJsongen(out *jwriter.Writer, in GameFull) {
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels(out *jwriter.Writer, in ScoredUser) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"description\":"
+		const prefix string = ",\"score\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Description))
+		out.Int32(int32(in.Score))
 	}
 	{
-		const prefix string = ",\"rules\":"
+		const prefix string = ",\"id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Rules))
+		out.Int64(int64(in.ID))
 	}
 	{
-		const prefix string = ",\"code_example\":"
+		const prefix string = ",\"active\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.CodeExample))
+		out.Bool(bool(in.Active))
 	}
 	{
-		const prefix string = ",\"bot_code\":"
+		const prefix string = ",\"username\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.BotCode))
+		out.String(string(in.Username))
 	}
 	{
-		const prefix string = ",\"logo_uuid\":"
+		const prefix string = ",\"photo_uuid\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.LogoUUID))
+		out.String(string(in.PhotoUUID))
 	}
-	{
-		const prefix string = ",\"slug\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScoredUser) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScoredUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScoredUser) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScoredUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels(l, v)
+}
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels1(in *jlexer.Lexer, out *PlayersCount) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
 		}
-		out.String(string(in.Slug))
+		in.Skip()
+		return
 	}
-	{
-		const prefix string = ",\"title\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeString()
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
 		}
-		out.String(string(in.Title))
+		switch key {
+		case "count":
+			out.Count = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
 	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels1(out *jwriter.Writer, in PlayersCount) {
+	out.RawByte('{')
+	first := true
+	_ = first
 	{
-		const prefix string = ",\"background_uuid\":"
+		const prefix string = ",\"count\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.BackgroundUUID))
+		out.Int64(int64(in.Count))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v GameFull) MarshalJSON() ([]byte, error) {
+func (v PlayersCount) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6601e8cdEncodeJsongen(&w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels1(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v GameFull) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6601e8cdEncodeJsongen(w, v)
+func (v PlayersCount) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels1(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *GameFull) UnmarshalJSON(data []byte) error {
+func (v *PlayersCount) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6601e8cdDecodeJsongen(&r, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels1(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *GameFull) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6601e8cdDecodeJsongen(l, v)
+func (v *PlayersCount) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels1(l, v)
 }
-func easyjson6601e8cdDecodeJsongen1(in *jlexer.Lexer, out *Game) {
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels2(in *jlexer.Lexer, out *InfoUser) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -192,12 +227,14 @@ func easyjson6601e8cdDecodeJsongen1(in *jlexer.Lexer, out *Game) {
 			continue
 		}
 		switch key {
-		case "slug":
-			out.Slug = string(in.String())
-		case "title":
-			out.Title = string(in.String())
-		case "background_uuid":
-			out.BackgroundUUID = string(in.String())
+		case "id":
+			out.ID = int64(in.Int64())
+		case "active":
+			out.Active = bool(in.Bool())
+		case "username":
+			out.Username = string(in.String())
+		case "photo_uuid":
+			out.PhotoUUID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -208,67 +245,77 @@ func easyjson6601e8cdDecodeJsongen1(in *jlexer.Lexer, out *Game) {
 		in.Consumed()
 	}
 }
-func easyjson6601e8cdEncodeJsongen1(out *jwriter.Writer, in Game) {
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels2(out *jwriter.Writer, in InfoUser) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"slug\":"
+		const prefix string = ",\"id\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Slug))
+		out.Int64(int64(in.ID))
 	}
 	{
-		const prefix string = ",\"title\":"
+		const prefix string = ",\"active\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Title))
+		out.Bool(bool(in.Active))
 	}
 	{
-		const prefix string = ",\"background_uuid\":"
+		const prefix string = ",\"username\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.BackgroundUUID))
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"photo_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.PhotoUUID))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v Game) MarshalJSON() ([]byte, error) {
+func (v InfoUser) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6601e8cdEncodeJsongen1(&w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels2(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v Game) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6601e8cdEncodeJsongen1(w, v)
+func (v InfoUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels2(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *Game) UnmarshalJSON(data []byte) error {
+func (v *InfoUser) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6601e8cdDecodeJsongen1(&r, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels2(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *Game) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6601e8cdDecodeJsongen1(l, v)
+func (v *InfoUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels2(l, v)
 }
-func easyjson6601e8cdDecodeJsongen2(in *jlexer.Lexer, out *ScoredUser) {
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels3(in *jlexer.Lexer, out *GameFull) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -287,16 +334,22 @@ func easyjson6601e8cdDecodeJsongen2(in *jlexer.Lexer, out *ScoredUser) {
 			continue
 		}
 		switch key {
-		case "score":
-			out.Score = int32(in.Int32())
-		case "id":
-			out.ID = int64(in.Int64())
-		case "active":
-			out.Active = bool(in.Bool())
-		case "username":
-			out.Username = string(in.String())
-		case "photo_uuid":
-			out.PhotoUUID = string(in.String())
+		case "description":
+			out.Description = string(in.String())
+		case "rules":
+			out.Rules = string(in.String())
+		case "code_example":
+			out.CodeExample = string(in.String())
+		case "bot_code":
+			out.BotCode = string(in.String())
+		case "logo_uuid":
+			out.LogoUUID = string(in.String())
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "background_uuid":
+			out.BackgroundUUID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -307,87 +360,117 @@ func easyjson6601e8cdDecodeJsongen2(in *jlexer.Lexer, out *ScoredUser) {
 		in.Consumed()
 	}
 }
-func easyjson6601e8cdEncodeJsongen2(out *jwriter.Writer, in ScoredUser) {
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels3(out *jwriter.Writer, in GameFull) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"score\":"
+		const prefix string = ",\"description\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int32(int32(in.Score))
+		out.String(string(in.Description))
 	}
 	{
-		const prefix string = ",\"id\":"
+		const prefix string = ",\"rules\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Int64(int64(in.ID))
+		out.String(string(in.Rules))
 	}
 	{
-		const prefix string = ",\"active\":"
+		const prefix string = ",\"code_example\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Bool(bool(in.Active))
+		out.String(string(in.CodeExample))
 	}
 	{
-		const prefix string = ",\"username\":"
+		const prefix string = ",\"bot_code\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Username))
+		out.String(string(in.BotCode))
 	}
 	{
-		const prefix string = ",\"photo_uuid\":"
+		const prefix string = ",\"logo_uuid\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.PhotoUUID))
+		out.String(string(in.LogoUUID))
+	}
+	{
+		const prefix string = ",\"slug\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"title\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"background_uuid\":"
+		if first {
+			first = false
+			out.RawString(prefix[1:])
+		} else {
+			out.RawString(prefix)
+		}
+		out.String(string(in.BackgroundUUID))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ScoredUser) MarshalJSON() ([]byte, error) {
+func (v GameFull) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6601e8cdEncodeJsongen2(&w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels3(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ScoredUser) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6601e8cdEncodeJsongen2(w, v)
+func (v GameFull) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels3(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ScoredUser) UnmarshalJSON(data []byte) error {
+func (v *GameFull) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6601e8cdDecodeJsongen2(&r, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels3(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ScoredUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6601e8cdDecodeJsongen2(l, v)
+func (v *GameFull) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels3(l, v)
 }
-func easyjson6601e8cdDecodeJsongen3(in *jlexer.Lexer, out *InfoUser) {
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels4(in *jlexer.Lexer, out *Game) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -406,14 +489,12 @@ func easyjson6601e8cdDecodeJsongen3(in *jlexer.Lexer, out *InfoUser) {
 			continue
 		}
 		switch key {
-		case "id":
-			out.ID = int64(in.Int64())
-		case "active":
-			out.Active = bool(in.Bool())
-		case "username":
-			out.Username = string(in.String())
-		case "photo_uuid":
-			out.PhotoUUID = string(in.String())
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "background_uuid":
+			out.BackgroundUUID = string(in.String())
 		default:
 			in.SkipRecursive()
 		}
@@ -424,77 +505,67 @@ func easyjson6601e8cdDecodeJsongen3(in *jlexer.Lexer, out *InfoUser) {
 		in.Consumed()
 	}
 }
-func easyjson6601e8cdEncodeJsongen3(out *jwriter.Writer, in InfoUser) {
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels4(out *jwriter.Writer, in Game) {
 	out.RawByte('{')
 	first := true
 	_ = first
 	{
-		const prefix string = ",\"id\":"
-		if first {
-			first = false
-			out.RawString(prefix[1:])
-		} else {
-			out.RawString(prefix)
-		}
-		out.Int64(int64(in.ID))
-	}
-	{
-		const prefix string = ",\"active\":"
+		const prefix string = ",\"slug\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.Bool(bool(in.Active))
+		out.String(string(in.Slug))
 	}
 	{
-		const prefix string = ",\"username\":"
+		const prefix string = ",\"title\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.Username))
+		out.String(string(in.Title))
 	}
 	{
-		const prefix string = ",\"photo_uuid\":"
+		const prefix string = ",\"background_uuid\":"
 		if first {
 			first = false
 			out.RawString(prefix[1:])
 		} else {
 			out.RawString(prefix)
 		}
-		out.String(string(in.PhotoUUID))
+		out.String(string(in.BackgroundUUID))
 	}
 	out.RawByte('}')
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v InfoUser) MarshalJSON() ([]byte, error) {
+func (v Game) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6601e8cdEncodeJsongen3(&w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels4(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v InfoUser) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6601e8cdEncodeJsongen3(w, v)
+func (v Game) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels4(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *InfoUser) UnmarshalJSON(data []byte) error {
+func (v *Game) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6601e8cdDecodeJsongen3(&r, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels4(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *InfoUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6601e8cdDecodeJsongen3(l, v)
+func (v *Game) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels4(l, v)
 }
-func easyjson6601e8cdDecodeJsongen4(in *jlexer.Lexer, out *BasicUser) {
+func easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels5(in *jlexer.Lexer, out *BasicUser) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -527,7 +598,7 @@ func easyjson6601e8cdDecodeJsongen4(in *jlexer.Lexer, out *BasicUser) {
 		in.Consumed()
 	}
 }
-func easyjson6601e8cdEncodeJsongen4(out *jwriter.Writer, in BasicUser) {
+func easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels5(out *jwriter.Writer, in BasicUser) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -557,23 +628,23 @@ func easyjson6601e8cdEncodeJsongen4(out *jwriter.Writer, in BasicUser) {
 // MarshalJSON supports json.Marshaler interface
 func (v BasicUser) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjson6601e8cdEncodeJsongen4(&w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels5(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v BasicUser) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjson6601e8cdEncodeJsongen4(w, v)
+	easyjson6601e8cdEncodeGithubComHotCodeGroupWarscriptGamesJmodels5(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *BasicUser) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjson6601e8cdDecodeJsongen4(&r, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels5(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *BasicUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjson6601e8cdDecodeJsongen4(l, v)
+	easyjson6601e8cdDecodeGithubComHotCodeGroupWarscriptGamesJmodels5(l, v)
 }
@@ -0,0 +1,12 @@
+package jmodels
+
+import "time"
+
+// UserGame игра, в которую играл пользователь, и его место в ней
+type UserGame struct {
+	Game
+	Score      int32     `json:"score"`
+	Rank       int64     `json:"rank"`
+	Percentile float64   `json:"percentile"`
+	LastPlayed time.Time `json:"last_played"`
+}
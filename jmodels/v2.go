@@ -0,0 +1,38 @@
+package jmodels
+
+// User игрок в ответах /v2: вложен в строку leaderboard, а не развёрнут в неё
+type User struct {
+	ID        int64  `json:"id"`
+	Username  string `json:"username"`
+	PhotoUUID string `json:"photo_uuid"`
+	Active    bool   `json:"active"`
+}
+
+// ScoredUserV2 строка leaderboard игры в /v2
+type ScoredUserV2 struct {
+	User  *User `json:"user"`
+	Score int32 `json:"score"`
+}
+
+// RankedUserV2 строка leaderboard по группе игроков в /v2
+type RankedUserV2 struct {
+	User       *User `json:"user"`
+	Score      int32 `json:"score"`
+	Rank       int64 `json:"rank"`
+	GlobalRank int64 `json:"global_rank"`
+}
+
+// WindowLeaderV2 строка leaderboard за окно в /v2
+type WindowLeaderV2 struct {
+	User   *User `json:"user"`
+	Points int64 `json:"points"`
+	Rank   int64 `json:"rank"`
+}
+
+// GlobalLeaderV2 строка общего рейтинга в /v2
+type GlobalLeaderV2 struct {
+	User        *User   `json:"user"`
+	Rating      float64 `json:"rating"`
+	Rank        int64   `json:"rank"`
+	GamesPlayed int32   `json:"games_played"`
+}
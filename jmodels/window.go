@@ -0,0 +1,8 @@
+package jmodels
+
+// WindowLeader игрок в leaderboard за день/неделю/месяц
+type WindowLeader struct {
+	InfoUser
+	Points int64 `json:"points"`
+	Rank   int64 `json:"rank"`
+}
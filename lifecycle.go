@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultDrainDelay сколько ждём после снятия регистрации, чтобы
+	// клиенты и балансировщики перестали слать нам новые запросы
+	defaultDrainDelay = 3 * time.Second
+	// defaultShutdownTimeout сколько даём на завершение запросов в полёте
+	defaultShutdownTimeout = 5 * time.Second
+)
+
+// httpShutdowner *http.Server
+type httpShutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// grpcStopper *grpc.Server
+type grpcStopper interface {
+	GracefulStop()
+	Stop()
+}
+
+// lifecycle порядок остановки сервиса по SIGTERM
+type lifecycle struct {
+	// deregister снимает регистрацию в discovery
+	deregister func()
+	drainDelay time.Duration
+	timeout    time.Duration
+
+	httpServer httpShutdowner
+	grpcServer grpcStopper
+	// stopBackground останавливает фоновые задачи, которые ходят в базу
+	stopBackground func()
+	// closeDB закрывает соединение с postgres последним, когда запросов уже нет
+	closeDB func() error
+
+	sleep  func(time.Duration)
+	logger *logrus.Logger
+}
+
+// wait блокируется до сигнала или до падения одного из серверов.
+// Ошибка сервера возвращается, сигнал -- это штатная остановка
+func (l *lifecycle) wait(signals <-chan os.Signal, serveErrs <-chan error) error {
+	select {
+	case sig := <-signals:
+		l.logger.Infof("[SIGNAL] got %s, shutting down", sig)
+		return nil
+	case err := <-serveErrs:
+		return err
+	}
+}
+
+// shutdown снимает регистрацию, ждёт drainDelay, дожидается запросов
+// в полёте не дольше timeout и только потом закрывает базу
+func (l *lifecycle) shutdown() error {
+	if l.deregister != nil {
+		l.deregister()
+	}
+
+	sleep := l.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+	sleep(l.drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+	defer cancel()
+
+	var shutdownErr error
+	grpcDone := make(chan struct{})
+	go func() {
+		l.grpcServer.GracefulStop()
+		close(grpcDone)
+	}()
+
+	if err := l.httpServer.Shutdown(ctx); err != nil {
+		shutdownErr = errors.Wrap(err, "http server shutdown")
+	}
+
+	select {
+	case <-grpcDone:
+	case <-ctx.Done():
+		// GracefulStop ждёт стримы бесконечно, дальше рвём соединения
+		l.grpcServer.Stop()
+		<-grpcDone
+		if shutdownErr == nil {
+			shutdownErr = errors.New("grpc server did not stop in time")
+		}
+	}
+
+	if l.stopBackground != nil {
+		l.stopBackground()
+	}
+
+	if l.closeDB != nil {
+		if err := l.closeDB(); err != nil && shutdownErr == nil {
+			shutdownErr = errors.Wrap(err, "can not close postgresql connection")
+		}
+	}
+
+	return shutdownErr
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// shutdownRecorder записывает шаги остановки по порядку
+type shutdownRecorder struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (r *shutdownRecorder) add(step string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.steps = append(r.steps, step)
+}
+
+type fakeHTTPServer struct {
+	rec *shutdownRecorder
+	// inFlight запрос, который ещё обрабатывается
+	inFlight time.Duration
+}
+
+func (s *fakeHTTPServer) Shutdown(ctx context.Context) error {
+	select {
+	case <-time.After(s.inFlight):
+		s.rec.add("http shutdown")
+		return nil
+	case <-ctx.Done():
+		s.rec.add("http shutdown timeout")
+		return ctx.Err()
+	}
+}
+
+type fakeGRPCServer struct {
+	rec     *shutdownRecorder
+	stopped chan struct{}
+	once    sync.Once
+	// hang GracefulStop не дождётся стримов
+	hang bool
+}
+
+func (s *fakeGRPCServer) GracefulStop() {
+	if s.hang {
+		<-s.stopped
+		return
+	}
+	s.rec.add("grpc graceful stop")
+}
+
+func (s *fakeGRPCServer) Stop() {
+	s.rec.add("grpc stop")
+	s.once.Do(func() { close(s.stopped) })
+}
+
+func newTestLifecycle(rec *shutdownRecorder, inFlight time.Duration, hang bool) *lifecycle {
+	return &lifecycle{
+		deregister: func() { rec.add("deregister") },
+		drainDelay: 3 * time.Second,
+		timeout:    50 * time.Millisecond,
+		httpServer: &fakeHTTPServer{rec: rec, inFlight: inFlight},
+		grpcServer: &fakeGRPCServer{rec: rec, stopped: make(chan struct{}), hang: hang},
+		stopBackground: func() {
+			rec.add("stop background")
+		},
+		closeDB: func() error {
+			rec.add("close db")
+			return nil
+		},
+		sleep: func(d time.Duration) {
+			rec.add("drain " + d.String())
+		},
+	}
+}
+
+func TestLifecycleShutdownOrder(t *testing.T) {
+	rec := &shutdownRecorder{}
+	lc := newTestLifecycle(rec, 0, false)
+
+	if err := lc.shutdown(); err != nil {
+		t.Fatalf("TestLifecycleShutdownOrder got unexpected error: %v", err)
+	}
+
+	// grpc и http останавливаются параллельно
+	if len(rec.steps) != 6 {
+		t.Fatalf("TestLifecycleShutdownOrder got unexpected steps: %v", rec.steps)
+	}
+	servers := rec.steps[2:4]
+	if !(servers[0] == "http shutdown" && servers[1] == "grpc graceful stop") &&
+		!(servers[0] == "grpc graceful stop" && servers[1] == "http shutdown") {
+		t.Errorf("TestLifecycleShutdownOrder got unexpected server steps: %v", servers)
+	}
+	rec.steps = append(rec.steps[:2], rec.steps[4:]...)
+
+	expected := []string{"deregister", "drain 3s", "stop background", "close db"}
+	if !reflect.DeepEqual(rec.steps, expected) {
+		t.Errorf("TestLifecycleShutdownOrder got %v, expected %v", rec.steps, expected)
+	}
+}
+
+func TestLifecycleShutdownTimeout(t *testing.T) {
+	rec := &shutdownRecorder{}
+	lc := newTestLifecycle(rec, time.Hour, true)
+
+	if err := lc.shutdown(); err == nil {
+		t.Error("TestLifecycleShutdownTimeout expected error")
+	}
+
+	expected := []string{"deregister", "drain 3s", "http shutdown timeout", "grpc stop", "stop background", "close db"}
+	if !reflect.DeepEqual(rec.steps, expected) {
+		t.Errorf("TestLifecycleShutdownTimeout got %v, expected %v", rec.steps, expected)
+	}
+}
+
+func TestLifecycleWait(t *testing.T) {
+	lc := &lifecycle{logger: newTestLogger()}
+
+	signals := make(chan os.Signal, 1)
+	signals <- os.Interrupt
+	if err := lc.wait(signals, make(chan error)); err != nil {
+		t.Errorf("TestLifecycleWait got unexpected error on signal: %v", err)
+	}
+
+	serveErrs := make(chan error, 1)
+	serveErrs <- errors.New("address already in use")
+	if err := lc.wait(make(chan os.Signal), serveErrs); err == nil {
+		t.Error("TestLifecycleWait expected server error")
+	}
+}
@@ -1,36 +1,36 @@
 package main
 
 import (
-	"fmt"
+	"flag"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
-	"github.com/HotCodeGroup/warscript-utils/balancer"
+	"github.com/HotCodeGroup/warscript-games/gmodels"
+
 	"github.com/HotCodeGroup/warscript-utils/logging"
 	"github.com/HotCodeGroup/warscript-utils/middlewares"
 	"github.com/HotCodeGroup/warscript-utils/models"
-	"github.com/HotCodeGroup/warscript-utils/postgresql"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-
-	consulapi "github.com/hashicorp/consul/api"
-	vaultapi "github.com/hashicorp/vault/api"
 )
 
-var authGPRC models.AuthClient
-var logger *logrus.Logger
-
-func deregisterService(consul *consulapi.Client, id string) {
-	err := consul.Agent().ServiceDeregister(id)
+func deregisterService(disc Discovery, id string, logger *logrus.Logger) {
+	err := disc.Deregister(id)
 	if err != nil {
 		logger.Errorf("can not derigister %s service: %s", id, err)
 	}
@@ -39,141 +39,211 @@ func deregisterService(consul *consulapi.Client, id string) {
 
 func main() {
 	// коннекстим логер
-	var err error
-	logger, err = logging.NewLogger(os.Stdout, os.Getenv("LOGENTRIESRUS_TOKEN"))
+	logger, err := logging.NewLogger(os.Stdout, os.Getenv("LOGENTRIESRUS_TOKEN"))
 	if err != nil {
 		log.Printf("can not create logger: %s", err)
 		return
 	}
 
-	// коннектим консул
-	consulConfig := consulapi.DefaultConfig()
-	consulConfig.Address = os.Getenv("CONSUL_ADDR")
-	consul, err := consulapi.NewClient(consulConfig)
+	// без подкоманды стартуем сервер, как и раньше
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		logger.Errorf("unknown command %q\n%s", name, commandsUsage())
+		os.Exit(2)
+	}
+
+	if err = cmd.run(logger, args); err != nil {
+		logger.Errorf("%s failed: %s", name, err)
+		os.Exit(1)
+	}
+}
+
+// runServe стартует http и grpc сервера
+func runServe(logger *logrus.Logger, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	cfg, err := parseConfig(fs, args)
 	if err != nil {
-		logger.Errorf("can not connect consul service: %s", err)
-		return
+		return err
 	}
 
-	// коннектим волт
-	vaultConfig := vaultapi.DefaultConfig()
-	vaultConfig.Address = os.Getenv("VAULT_ADDR")
-	vault, err := vaultapi.NewClient(vaultConfig)
+	// коннектим консул, если он есть
+	consul, err := connectConsul(cfg)
 	if err != nil {
-		logger.Errorf("can not connect vault service: %s", err)
-		return
+		return err
 	}
-	vault.SetToken(os.Getenv("VAULT_TOKEN"))
 
 	// получаем порты, на которых будем стартовать
-	httpPort, grpcPort, err := balancer.GetPorts("warscript-games/bounds", "warscript-games", consul)
+	httpPort, grpcPort, err := getPorts(cfg, consul)
 	if err != nil {
-		logger.Errorf("can not find empry port: %s", err)
-		return
+		return err
 	}
 
-	// получаем конфиг на постгрес и стартуем
-	postgreConf, err := vault.Logical().Read("warscript-games/postgres")
-	if err != nil || postgreConf == nil || len(postgreConf.Warnings) != 0 {
-		logger.Errorf("can read warscript-games/postges key: %+v; %+v", err, postgreConf)
-		return
+	disc, err := newDiscovery(cfg, consul, logger)
+	if err != nil {
+		return err
 	}
 
-	pqConn, err = postgresql.Connect(postgreConf.Data["user"].(string), postgreConf.Data["pass"].(string),
-		postgreConf.Data["host"].(string), postgreConf.Data["port"].(string), postgreConf.Data["database"].(string))
+	// коннектим постгрес
+	db, err := connectPostgres(cfg)
 	if err != nil {
-		logger.Errorf("can not connect to postgresql database: %s", err.Error())
-		return
+		return err
+	}
+	defer db.Close()
+
+	// на чужой схеме не стартуем, миграции катит `warscript-games migrate`
+	if err = checkSchemaVersion(db); err != nil {
+		return err
 	}
-	defer pqConn.Close()
 
 	// коннектимся к серверу warscript-users по grpc
-	authGPRCConn, err := balancer.ConnectClient(consul, "warscript-users-grpc")
+	auth, authGPRCConn, err := connectAuth(disc, logger)
 	if err != nil {
-		logger.Errorf("can not connect to auth grpc: %s", err.Error())
-		return
+		return err
+	}
+	if authGPRCConn != nil {
+		defer authGPRCConn.Close()
 	}
-	defer authGPRCConn.Close()
-	authGPRC = models.NewAuthClient(authGPRCConn)
+
+	srv := NewServer(cfg, NewAccessObject(db, auth, cfg.UsersTimeout), auth, logger)
+
+	// проверки для /readyz, gRPC health и discovery
+	checker := newHealthChecker(db, authGPRCConn)
 
 	// регаем http сервис
-	httpServiceID := fmt.Sprintf("warscript-games-http:%d", httpPort)
-	err = consul.Agent().ServiceRegister(&consulapi.AgentServiceRegistration{
-		ID:      httpServiceID,
-		Name:    "warscript-games-http",
-		Port:    httpPort,
-		Address: "127.0.0.1",
-	})
+	httpServiceID, err := disc.Register("warscript-games-http", httpPort, &ServiceCheck{HTTPPath: "/readyz"})
 	if err != nil {
-		logger.Errorf("can not register warscript-games-http: %s", err.Error())
-		return
+		return err
 	}
-	defer deregisterService(consul, httpServiceID)
 
 	// регаем grpc сервис
-	grpcServiceID := fmt.Sprintf("warscript-games-grpc:%d", grpcPort)
-	err = consul.Agent().ServiceRegister(&consulapi.AgentServiceRegistration{
-		ID:      grpcServiceID,
-		Name:    "warscript-games-grpc",
-		Port:    grpcPort,
-		Address: "127.0.0.1",
-	})
+	grpcServiceID, err := disc.Register("warscript-games-grpc", grpcPort, &ServiceCheck{GRPC: true})
 	if err != nil {
-		logger.Errorf("can not register warscript-games-grpc: %s", err.Error())
-		return
+		deregisterService(disc, httpServiceID, logger)
+		return err
 	}
-	defer deregisterService(consul, grpcServiceID)
+
+	// после регистрации выходим только через lifecycle.shutdown,
+	// чтобы не оставить себя в discovery
+	serveErrs := make(chan error, 2)
 
 	// стартуем свой grpc
-	games := &GamesManager{}
+	games := srv.GamesManager()
+	serverGRPCGames := grpc.NewServer(srv.grpcServerOptions()...)
+	models.RegisterGamesServer(serverGRPCGames, games)
+	gmodels.RegisterLeaderboardsServer(serverGRPCGames, games)
+	healthGRPC := health.NewServer()
+	healthpb.RegisterHealthServer(serverGRPCGames, healthGRPC)
+
 	listenGRPCPort, err := net.Listen("tcp", ":"+strconv.Itoa(grpcPort))
 	if err != nil {
-		logger.Errorf("grpc port listener error: %s", err)
-		return
+		serveErrs <- errors.Wrap(err, "grpc port listener error")
+	} else {
+		logger.Infof("Games gRPC service successfully started at port %d", grpcPort)
+		go func() {
+			if err := serverGRPCGames.Serve(listenGRPCPort); err != nil {
+				serveErrs <- errors.Wrapf(err, "Games gRPC service failed at port %d", grpcPort)
+			}
+		}()
 	}
 
-	serverGRPCGames := grpc.NewServer()
-	models.RegisterGamesServer(serverGRPCGames, games)
-	logger.Infof("Games gRPC service successfully started at port %d", grpcPort)
+	// фоновые задачи: сохраняем закрывшиеся окна leaderboard по времени
+	// и обновляем метрики по играм и статус gRPC health, отправляем спаны
+	stopBackground := make(chan struct{})
+	background := &sync.WaitGroup{}
+	background.Add(4)
 	go func() {
-		if err := serverGRPCGames.Serve(listenGRPCPort); err != nil {
-			logger.Fatalf("Games gRPC service failed at port %d", grpcPort)
-			os.Exit(1)
-		}
+		defer background.Done()
+		srv.runWindowSnapshots(10*time.Minute, stopBackground)
+	}()
+	go func() {
+		defer background.Done()
+		srv.runGameGauges(time.Minute, stopBackground)
+	}()
+	go func() {
+		defer background.Done()
+		srv.tracer.run(stopBackground)
+	}()
+	go func() {
+		defer background.Done()
+		checker.runGRPCHealth(healthGRPC, stopBackground)
 	}()
 
-	signals := make(chan os.Signal, 1)
-	signal.Notify(signals, os.Kill, os.Interrupt, syscall.SIGTERM)
-
+	// стартуем http
+	serverHTTP := &http.Server{
+		Addr:    ":" + strconv.Itoa(httpPort),
+		Handler: srv.httpHandler(checker),
+	}
+	logger.Infof("Games HTTP service successfully started at port %d", httpPort)
 	go func() {
-		<-signals
+		if err := serverHTTP.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErrs <- errors.Wrap(err, "cant start main server")
+		}
+	}()
 
-		// вырубили http
-		deregisterService(consul, httpServiceID)
-		// вырубили grpc
-		deregisterService(consul, grpcServiceID)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	lc := &lifecycle{
+		deregister: func() {
+			// перестали быть ready для всех, кто проверяет нас напрямую
+			checker.setShuttingDown()
+			healthGRPC.Shutdown()
+			// вырубили http
+			deregisterService(disc, httpServiceID, logger)
+			// вырубили grpc
+			deregisterService(disc, grpcServiceID, logger)
+		},
+		drainDelay: time.Duration(cfg.DrainDelay),
+		timeout:    time.Duration(cfg.ShutdownTimeout),
+		httpServer: serverHTTP,
+		grpcServer: serverGRPCGames,
+		stopBackground: func() {
+			close(stopBackground)
+			background.Wait()
+		},
 		// отрубили базули
-		pqConn.Close()
-		logger.Info("successfully closed warscript-users postgreSQL connection")
-
-		logger.Infof("[SIGNAL] Stopped by signal!")
-		os.Exit(0)
-	}()
+		closeDB: db.Close,
+		logger:  logger,
+	}
 
-	// стартуем http
-	r := mux.NewRouter().PathPrefix("/v1").Subrouter()
-	r.HandleFunc("/games", GetGameList).Methods("GET")
-	r.HandleFunc("/games/{game_slug}", GetGame).Methods("GET")
-	r.HandleFunc("/games/{game_slug}/leaderboard", GetGameLeaderboard).Methods("GET")
-	r.HandleFunc("/games/{game_slug}/leaderboard/count", GetGameTotalPlayers).Methods("GET")
+	serveErr := lc.wait(signals, serveErrs)
+	if err = lc.shutdown(); err != nil {
+		logger.Errorf("shutdown: %s", err)
+	} else {
+		logger.Info("successfully stopped warscript-games")
+	}
 
-	http.Handle("/metrics", promhttp.Handler())
-	http.Handle("/", middlewares.RecoverMiddleware(middlewares.AccessLogMiddleware(r, logger), logger))
+	return serveErr
+}
 
-	logger.Infof("Games HTTP service successfully started at port %d", httpPort)
-	err = http.ListenAndServe(":"+strconv.Itoa(httpPort), nil)
-	if err != nil {
-		logger.Errorf("cant start main server. err: %s", err.Error())
-		return
+// httpHandler роутинг http api
+func (s *Server) httpHandler(checker *healthChecker) http.Handler {
+	r := mux.NewRouter()
+	r.Use(s.httpTracingMiddleware, httpMetricsMiddleware)
+	r.NotFoundHandler = http.HandlerFunc(notFoundHandler)
+	r.MethodNotAllowedHandler = http.HandlerFunc(methodNotAllowedHandler)
+
+	// хэндлеры у всех версий одни и те же, версии отличаются формой ответов
+	routes := s.apiRoutes()
+	for _, v := range apiVersions {
+		sub := r.PathPrefix(v.prefix()).Subrouter()
+		sub.Use(versionMiddleware(v))
+		for _, route := range routes {
+			sub.HandleFunc(route.path, s.withAuth(route)).Methods(route.method)
+		}
+		sub.HandleFunc("/openapi.json", openAPIHandler(buildOpenAPI(v, routes))).Methods("GET")
 	}
+
+	root := http.NewServeMux()
+	root.Handle("/metrics", promhttp.Handler())
+	root.HandleFunc("/healthz", checker.Healthz)
+	root.HandleFunc("/readyz", checker.Readyz)
+	root.Handle("/", middlewares.RecoverMiddleware(middlewares.AccessLogMiddleware(r, s.logger), s.logger))
+
+	return root
 }
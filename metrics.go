@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+// Метки у всех метрик ограничены: шаблон роута, а не путь, имя метода DAO,
+// код ответа и slug игры, которых единицы
+var (
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Время обработки http запросов по роутам и кодам ответа",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Время одного SQL запроса по методам DAO, без походов в другие сервисы",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+	usersDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "users_request_duration_seconds",
+		Help:    "Время запросов в warscript-users",
+		Buckets: prometheus.DefBuckets,
+	})
+	usersErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "users_request_errors_total",
+		Help: "Ошибки запросов в warscript-users по кодам gRPC",
+	}, []string{"code"})
+	statsCacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "leaderboard_stats_cache_requests_total",
+		Help: "Обращения к кэшу распределений очков: hit или miss",
+	}, []string{"result"})
+	gamesTotal = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "games_total",
+		Help: "Количество игр",
+	})
+	gamePlayers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "game_players",
+		Help: "Количество игроков в leaderboard игры",
+	}, []string{"game"})
+)
+
+func init() {
+	prometheus.MustRegister(httpDuration, dbDuration, usersDuration, usersErrors,
+		statsCacheRequests, gamesTotal, gamePlayers)
+}
+
+// statusRecorder запоминает код ответа. Flush пробрасываем, иначе
+// выгрузка leaderboard перестанет отдаваться по частям
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// routeTemplate шаблон роута mux, по которому пришёл запрос: по нему,
+// а не по пути с ID, группируем метрики и спаны
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if tpl, err := current.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+
+	return "unknown"
+}
+
+// httpMetricsMiddleware время обработки запроса по шаблону роута
+func httpMetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		httpDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.code)).
+			Observe(time.Since(start).Seconds())
+	})
+}
+
+// observeUsersCall время и ошибки запроса в warscript-users
+func observeUsersCall(start time.Time, err error) {
+	usersDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		usersErrors.WithLabelValues(status.Code(err).String()).Inc()
+	}
+}
+
+// observeStatsCache попадание в кэш распределений очков
+func observeStatsCache(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	statsCacheRequests.WithLabelValues(result).Inc()
+}
+
+// updateGameGauges пересчитывает количество игр и игроков в каждой
+func (s *Server) updateGameGauges(ctx context.Context) {
+	ctx, cancel := s.queryContext(ctx)
+	defer cancel()
+
+	games, err := s.games.GetGameList(ctx)
+	if err != nil {
+		s.logger.Errorf("can not update games gauges: %s", err)
+		return
+	}
+	gamesTotal.Set(float64(len(games)))
+
+	for _, game := range games {
+		players, err := s.games.GetGameTotalPlayersBySlug(ctx, game.Slug)
+		if err != nil {
+			s.logger.Errorf("can not update players gauge of %s: %s", game.Slug, err)
+			continue
+		}
+		gamePlayers.WithLabelValues(game.Slug).Set(float64(players))
+	}
+}
+
+// runGameGauges периодически обновляет gauges по играм. Считать их на каждый
+// scrape дорого, а точность до interval тут не нужна
+func (s *Server) runGameGauges(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.updateGameGauges(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.updateGameGauges(ctx)
+		case <-stop:
+			return
+		}
+	}
+}
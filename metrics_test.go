@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/grpc"
+)
+
+func TestHTTPMetricsMiddleware(t *testing.T) {
+	r := mux.NewRouter()
+	r.Use(httpMetricsMiddleware)
+	r.HandleFunc("/metrics-test/{game_slug}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	for _, slug := range []string{"pong", "ping"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/metrics-test/"+slug, nil))
+	}
+
+	// оба запроса попали в один роут, а не в отдельные пути
+	m := &dto.Metric{}
+	observer := httpDuration.WithLabelValues("/metrics-test/{game_slug}", "GET", "418")
+	if err := observer.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("TestHTTPMetricsMiddleware got unexpected error: %s", err)
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 2 {
+		t.Errorf("TestHTTPMetricsMiddleware got %d observations, expected 2", got)
+	}
+}
+
+func TestStatsCacheMetrics(t *testing.T) {
+	srv := initTests()
+	hits := testutil.ToFloat64(statsCacheRequests.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(statsCacheRequests.WithLabelValues("miss"))
+
+	for i := 0; i < 2; i++ {
+		if _, err := srv.getGameScoreStatsImpl(context.Background(), "pong", 3); err != nil {
+			t.Fatalf("TestStatsCacheMetrics got unexpected error: %s", err)
+		}
+	}
+
+	if got := testutil.ToFloat64(statsCacheRequests.WithLabelValues("miss")) - misses; got != 1 {
+		t.Errorf("TestStatsCacheMetrics got %v misses, expected 1", got)
+	}
+	if got := testutil.ToFloat64(statsCacheRequests.WithLabelValues("hit")) - hits; got != 1 {
+		t.Errorf("TestStatsCacheMetrics got %v hits, expected 1", got)
+	}
+}
+
+func TestUpdateGameGauges(t *testing.T) {
+	srv := initTests()
+	srv.updateGameGauges(context.Background())
+
+	if got := testutil.ToFloat64(gamesTotal); got != 1 {
+		t.Errorf("TestUpdateGameGauges got %v games, expected 1", got)
+	}
+	if got := testutil.ToFloat64(gamePlayers.WithLabelValues("pong")); got != 1 {
+		t.Errorf("TestUpdateGameGauges got %v players, expected 1", got)
+	}
+}
+
+// slowAuthClient warscript-users, который отвечает не сразу
+type slowAuthClient struct {
+	fakeAuthClient
+	delay time.Duration
+}
+
+func (c *slowAuthClient) GetUsersByIDs(ctx context.Context,
+	in *models.UserIDs, opts ...grpc.CallOption) (*models.InfoUsers, error) {
+	time.Sleep(c.delay)
+	return c.fakeAuthClient.GetUsersByIDs(ctx, in, opts...)
+}
+
+func dbDurationSample(t *testing.T, method string) (uint64, float64) {
+	m := &dto.Metric{}
+	observer := dbDuration.WithLabelValues(method)
+	if err := observer.(prometheus.Metric).Write(m); err != nil {
+		t.Fatalf("dbDurationSample got unexpected error: %s", err)
+	}
+
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestDBQueryDurationWithoutUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs("pong", 0, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "score"}).
+			AddRow(1, 200))
+
+	delay := 200 * time.Millisecond
+	gs := NewAccessObject(db, &slowAuthClient{delay: delay}, 0)
+
+	count, sum := dbDurationSample(t, "GetGameLeaderboardBySlug")
+	if _, err = gs.GetGameLeaderboardBySlug(context.Background(), "pong", 6, 0); err != nil {
+		t.Fatalf("TestDBQueryDurationWithoutUsers got unexpected error: %s", err)
+	}
+	gotCount, gotSum := dbDurationSample(t, "GetGameLeaderboardBySlug")
+
+	// один запрос, и поход в warscript-users в его время не попал
+	if gotCount-count != 1 {
+		t.Errorf("TestDBQueryDurationWithoutUsers got %d observations, expected 1", gotCount-count)
+	}
+	if gotSum-sum >= delay.Seconds() {
+		t.Errorf("TestDBQueryDurationWithoutUsers got %vs, users delay %v leaked in", gotSum-sum, delay)
+	}
+}
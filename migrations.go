@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// migrationsLockID ключ pg_advisory_lock: миграции из нескольких
+// контейнеров, стартовавших одновременно, выполняются по очереди
+const migrationsLockID = 7272019
+
+// migration одна версия схемы
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// latestSchemaVersion версия схемы, с которой работает этот бинарник
+func latestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// findMigration миграция версии version
+func findMigration(version int) (*migration, bool) {
+	for _, m := range migrations {
+		if m.version == version {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// withMigrationLock выполняет fn на одном соединении под advisory lock.
+// Session-level lock привязан к соединению, поэтому нужен *sql.Conn, а не пул
+func withMigrationLock(db *sql.DB, fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "can not get migration connection")
+	}
+	//nolint: errcheck
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, migrationsLockID); err != nil {
+		return errors.Wrap(err, "can not take migration lock")
+	}
+
+	fnErr := fn(conn)
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1);`, migrationsLockID); err != nil && fnErr == nil {
+		return errors.Wrap(err, "can not release migration lock")
+	}
+
+	return fnErr
+}
+
+// currentSchemaVersion последняя применённая версия, 0 для пустой базы
+func currentSchemaVersion(conn *sql.Conn) (int, error) {
+	ctx := context.Background()
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations
+				(
+					version BIGINT NOT NULL
+						CONSTRAINT schema_migrations_pk
+							PRIMARY KEY,
+					name TEXT NOT NULL,
+					applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+				);`)
+	if err != nil {
+		return 0, errors.Wrap(err, "can not create schema_migrations")
+	}
+
+	var version int
+	err = conn.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM schema_migrations;`).Scan(&version)
+	if err != nil {
+		return 0, errors.Wrap(err, "can not get schema version")
+	}
+
+	return version, nil
+}
+
+// applyMigration применяет или откатывает m в отдельной транзакции
+// вместе с записью в schema_migrations
+func applyMigration(conn *sql.Conn, m *migration, up bool) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "can not open migration %d transaction", m.version)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	if up {
+		if _, err = tx.Exec(m.up); err != nil {
+			return errors.Wrapf(err, "migration %d_%s up failed", m.version, m.name)
+		}
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`, m.version, m.name)
+	} else {
+		if _, err = tx.Exec(m.down); err != nil {
+			return errors.Wrapf(err, "migration %d_%s down failed", m.version, m.name)
+		}
+		_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = $1;`, m.version)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "can not record migration %d", m.version)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrapf(err, "can not commit migration %d", m.version)
+	}
+
+	return nil
+}
+
+// migrateTo приводит схему к версии target, применяя миграции вверх
+// или откатывая вниз. Возвращает версию до изменений
+func migrateTo(db *sql.DB, target int, logger *logrus.Logger) (int, error) {
+	if target < 0 || target > latestSchemaVersion() {
+		return 0, errors.Errorf("unknown schema version %d, latest is %d", target, latestSchemaVersion())
+	}
+
+	var current int
+	err := withMigrationLock(db, func(conn *sql.Conn) error {
+		var err error
+		current, err = currentSchemaVersion(conn)
+		if err != nil {
+			return err
+		}
+		if current > latestSchemaVersion() {
+			return errors.Errorf("schema version %d is newer than this binary knows (%d)",
+				current, latestSchemaVersion())
+		}
+
+		applied := current
+		if current == 0 {
+			legacy, err := isLegacySchema(conn)
+			if err != nil {
+				return err
+			}
+			if legacy {
+				// ниже старую базу не откатываем: откат удалил бы таблицы с данными
+				if target < legacySchemaVersion {
+					return errors.Errorf("legacy sql/*.sql schema can not be migrated below version %d",
+						legacySchemaVersion)
+				}
+				if err = upgradeLegacySchema(conn); err != nil {
+					return err
+				}
+				logger.Infof("upgraded legacy sql/*.sql schema to version %d", legacySchemaVersion)
+				applied = legacySchemaVersion
+			}
+		}
+
+		for _, m := range migrations {
+			if m.version > applied && m.version <= target {
+				if err = applyMigration(conn, m, true); err != nil {
+					return err
+				}
+				logger.Infof("applied migration %d_%s", m.version, m.name)
+			}
+		}
+
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.version <= applied && m.version > target {
+				if err = applyMigration(conn, m, false); err != nil {
+					return err
+				}
+				logger.Infof("reverted migration %d_%s", m.version, m.name)
+			}
+		}
+
+		return nil
+	})
+
+	return current, err
+}
+
+// isLegacySchema база создана из старых sql/*.sql: миграций в ней
+// ещё не было, а таблицы уже есть
+func isLegacySchema(conn *sql.Conn) (bool, error) {
+	var legacy bool
+	err := conn.QueryRowContext(context.Background(), `SELECT to_regclass('games') IS NOT NULL
+				AND to_regclass('users_games') IS NOT NULL;`).Scan(&legacy)
+	if err != nil {
+		return false, errors.Wrap(err, "can not check legacy schema")
+	}
+
+	return legacy, nil
+}
+
+// upgradeLegacySchema доводит базу из sql/*.sql до legacySchemaVersion
+// и записывает пройденные версии в schema_migrations
+func upgradeLegacySchema(conn *sql.Conn) error {
+	ctx := context.Background()
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "can not open legacy upgrade transaction")
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	if _, err = tx.Exec(migrationLegacyUp); err != nil {
+		return errors.Wrap(err, "legacy schema upgrade failed")
+	}
+	for _, m := range migrations {
+		if m.version > legacySchemaVersion {
+			break
+		}
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`, m.version, m.name)
+		if err != nil {
+			return errors.Wrapf(err, "can not record migration %d", m.version)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "can not commit legacy upgrade")
+	}
+
+	return nil
+}
+
+// checkSchemaVersion не даёт стартовать на схеме, с которой бинарник не умеет работать
+func checkSchemaVersion(db *sql.DB) error {
+	var version int
+	err := db.QueryRow(`SELECT coalesce(max(version), 0) FROM schema_migrations;`).Scan(&version)
+	if err != nil {
+		return errors.Wrap(err, "can not get schema version, run `warscript-games migrate`")
+	}
+
+	if version != latestSchemaVersion() {
+		return errors.Errorf("unexpected schema version %d, expected %d: run `warscript-games migrate`",
+			version, latestSchemaVersion())
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package main
+
+// Схема базы. Миграции только добавляются в конец: уже применённые
+// на проде не меняем, а исправляем следующей миграцией
+
+var migrations = []*migration{
+	{version: 1, name: "games", up: migrationGamesUp, down: migrationGamesDown},
+	{version: 2, name: "users_games", up: migrationUsersGamesUp, down: migrationUsersGamesDown},
+	{version: 3, name: "score_events", up: migrationScoreEventsUp, down: migrationScoreEventsDown},
+	{version: 4, name: "follows", up: migrationFollowsUp, down: migrationFollowsDown},
+	{version: 5, name: "grants", up: migrationGrantsUp, down: migrationGrantsDown},
+}
+
+const migrationGamesUp = `
+CREATE EXTENSION IF NOT EXISTS CITEXT;
+
+CREATE TABLE "games"
+(
+	id bigserial not null
+		constraint game_pk
+			primary key,
+	slug CITEXT UNIQUE CONSTRAINT games_slug_check CHECK ( slug ~ '^(\d|\w|-|_)*(\w|-|_)(\d|\w|-|_)*$' ),
+	title CITEXT UNIQUE CONSTRAINT title_empty not null check ( title <> '' ),
+	description TEXT NOT NULL,
+	rules TEXT NOT NULL,
+	code_example TEXT NOT NULL,
+	bot_code TEXT NOT NULL,
+	logo_uuid UUID NOT NULL,
+	background_uuid UUID NOT NULL
+);
+`
+
+const migrationGamesDown = `
+DROP TABLE games;
+`
+
+const migrationUsersGamesUp = `
+CREATE TABLE "users_games"
+(
+	user_id BIGINT NOT NULL,
+	game_id BIGINT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	score INTEGER NOT NULL DEFAULT 0,
+	last_played TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT users_games_pk PRIMARY KEY (user_id, game_id)
+);
+` + migrationUsersGamesLastPlayedUp
+
+// migrationUsersGamesLastPlayedUp часть версии 2, которой нет в базах из sql/*.sql
+const migrationUsersGamesLastPlayedUp = `
+CREATE INDEX users_games_user_id_idx ON users_games (user_id);
+
+-- очки пишут другие сервисы, поэтому время последней игры
+-- обновляем триггером при любом изменении очков
+CREATE OR REPLACE FUNCTION users_games_touch_last_played() RETURNS TRIGGER AS
+$$
+BEGIN
+	NEW.last_played = now();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_games_last_played
+	BEFORE UPDATE OF score ON users_games
+	FOR EACH ROW
+EXECUTE PROCEDURE users_games_touch_last_played();
+`
+
+// legacySchemaVersion до какой версии migrationLegacyUp доводит базу,
+// созданную из старых sql/*.sql: games в ней такая же, как в версии 1,
+// а users_games -- как в версии 2 без last_played, индекса и триггера
+const legacySchemaVersion = 2
+
+const migrationLegacyUp = `
+-- когда играли раньше, неизвестно: старым записям достаётся время миграции
+ALTER TABLE users_games ADD COLUMN last_played TIMESTAMPTZ NOT NULL DEFAULT now();
+` + migrationUsersGamesLastPlayedUp
+
+const migrationUsersGamesDown = `
+DROP TABLE users_games;
+DROP FUNCTION users_games_touch_last_played();
+`
+
+const migrationScoreEventsUp = `
+CREATE TABLE "score_events"
+(
+	id bigserial NOT NULL
+		CONSTRAINT score_events_pk
+			PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	game_id BIGINT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	old_score INTEGER NOT NULL,
+	new_score INTEGER NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	source TEXT NOT NULL DEFAULT 'unknown',
+	match_id TEXT,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX score_events_game_id_created_at_idx ON score_events (game_id, created_at);
+CREATE INDEX score_events_user_id_game_id_idx ON score_events (user_id, game_id, created_at);
+
+-- очки пишут и другие сервисы, поэтому историю собираем триггером,
+-- то есть в той же транзакции, что и само изменение. Причину, сервис
+-- и матч писатель может передать через set_config('warscript.score_*', ..., true)
+CREATE OR REPLACE FUNCTION users_games_log_score() RETURNS TRIGGER AS
+$$
+DECLARE
+	old_score INTEGER := 0;
+BEGIN
+	IF TG_OP = 'UPDATE' THEN
+		IF NEW.score = OLD.score THEN
+			RETURN NEW;
+		END IF;
+		old_score := OLD.score;
+	END IF;
+
+	INSERT INTO score_events (user_id, game_id, old_score, new_score, reason, source, match_id)
+	VALUES (NEW.user_id, NEW.game_id, old_score, NEW.score,
+		coalesce(nullif(current_setting('warscript.score_reason', true), ''), ''),
+		coalesce(nullif(current_setting('warscript.score_source', true), ''), 'unknown'),
+		nullif(current_setting('warscript.score_match_id', true), ''));
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- история только дописывается: править и удалять события нельзя,
+-- кроме каскадного удаления вместе с игрой
+CREATE OR REPLACE FUNCTION score_events_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+	IF TG_OP = 'DELETE' AND NOT EXISTS(SELECT 1 FROM games WHERE id = OLD.game_id) THEN
+		RETURN OLD;
+	END IF;
+	RAISE EXCEPTION 'score_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER score_events_immutable
+	BEFORE UPDATE OR DELETE ON score_events
+	FOR EACH ROW
+EXECUTE PROCEDURE score_events_append_only();
+
+CREATE TRIGGER users_games_score_events
+	AFTER INSERT OR UPDATE OF score ON users_games
+	FOR EACH ROW
+EXECUTE PROCEDURE users_games_log_score();
+
+CREATE TABLE "leaderboard_snapshot_windows"
+(
+	game_id BIGINT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	period TEXT NOT NULL,
+	window_start TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT leaderboard_snapshot_windows_pk PRIMARY KEY (game_id, period, window_start)
+);
+
+CREATE TABLE "leaderboard_snapshots"
+(
+	game_id BIGINT NOT NULL,
+	period TEXT NOT NULL,
+	window_start TIMESTAMPTZ NOT NULL,
+	user_id BIGINT NOT NULL,
+	points BIGINT NOT NULL,
+	place BIGINT NOT NULL,
+	CONSTRAINT leaderboard_snapshots_pk PRIMARY KEY (game_id, period, window_start, user_id),
+	CONSTRAINT leaderboard_snapshots_window_fk FOREIGN KEY (game_id, period, window_start)
+		REFERENCES leaderboard_snapshot_windows (game_id, period, window_start) ON DELETE CASCADE
+);
+
+CREATE INDEX leaderboard_snapshots_place_idx ON leaderboard_snapshots (game_id, period, window_start, place);
+`
+
+const migrationScoreEventsDown = `
+DROP TABLE leaderboard_snapshots;
+DROP TABLE leaderboard_snapshot_windows;
+DROP TRIGGER users_games_score_events ON users_games;
+DROP TABLE score_events;
+DROP FUNCTION score_events_append_only();
+DROP FUNCTION users_games_log_score();
+`
+
+const migrationFollowsUp = `
+CREATE TABLE "follows"
+(
+	follower_id BIGINT NOT NULL,
+	followee_id BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT follows_pk PRIMARY KEY (follower_id, followee_id),
+	CONSTRAINT follows_self_check CHECK ( follower_id <> followee_id )
+);
+`
+
+const migrationFollowsDown = `
+DROP TABLE follows;
+`
+
+const migrationGrantsUp = `
+CREATE TABLE "grants"
+(
+	id bigserial NOT NULL
+		CONSTRAINT grants_pk
+			PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	-- NULL -- роль на все игры
+	game_id BIGINT REFERENCES games (id) ON DELETE CASCADE,
+	role TEXT NOT NULL CONSTRAINT grants_role_check CHECK ( role IN ('admin', 'moderator', 'author') ),
+	granted_by BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	CONSTRAINT grants_admin_global_check CHECK ( role <> 'admin' OR game_id IS NULL ),
+	CONSTRAINT grants_author_game_check CHECK ( role <> 'author' OR game_id IS NOT NULL )
+);
+
+CREATE UNIQUE INDEX grants_global_uniq ON grants (user_id, role) WHERE game_id IS NULL;
+CREATE UNIQUE INDEX grants_game_uniq ON grants (user_id, game_id, role) WHERE game_id IS NOT NULL;
+
+ALTER TABLE users_games ADD COLUMN hidden BOOLEAN NOT NULL DEFAULT false;
+
+-- текущие очки, которые показываем игрокам (leaderboard, статистика,
+-- выгрузка), читаются отсюда. Окна по score_events и leaderboard_snapshots
+-- отбрасывают скрытых сами при чтении
+CREATE VIEW visible_users_games AS
+	SELECT * FROM users_games WHERE NOT hidden;
+`
+
+const migrationGrantsDown = `
+DROP VIEW visible_users_games;
+ALTER TABLE users_games DROP COLUMN hidden;
+DROP TABLE grants;
+`
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/pkg/errors"
+)
+
+func TestMigrationsSequential(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %s has version %d, expected %d", m.name, m.version, i+1)
+		}
+		if strings.TrimSpace(m.up) == "" || strings.TrimSpace(m.down) == "" {
+			t.Errorf("migration %d_%s must have both up and down", m.version, m.name)
+		}
+		// схему больше не пересоздаём на живой базе
+		if strings.Contains(strings.ToUpper(m.up), "DROP TABLE") {
+			t.Errorf("migration %d_%s up drops a table", m.version, m.name)
+		}
+	}
+}
+
+// testPostgresDSNEnv база для тестов на настоящем postgres, без неё они
+// пропускаются. Схема в ней пересоздаётся с нуля, рабочую базу не указывать
+const testPostgresDSNEnv = "WARSCRIPT_TEST_POSTGRES_DSN"
+
+// openEmptyTestPostgres база без таблиц, даже если прошлый тест упал посередине
+func openEmptyTestPostgres(t *testing.T) *sql.DB {
+	dsn := os.Getenv(testPostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testPostgresDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("can not open test postgres: %v", err)
+	}
+	if _, err = db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public;`); err != nil {
+		db.Close()
+		t.Fatalf("can not clean test postgres: %v", err)
+	}
+
+	return db
+}
+
+// openTestPostgres пустая база с последней схемой
+func openTestPostgres(t *testing.T) *sql.DB {
+	db := openEmptyTestPostgres(t)
+	if _, err := migrateTo(db, latestSchemaVersion(), newTestLogger()); err != nil {
+		db.Close()
+		t.Fatalf("can not migrate test postgres: %v", err)
+	}
+
+	return db
+}
+
+func expectMigrationVersion(mock sqlmock.Sqlmock, version int) {
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1);")).WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT coalesce\\(max\\(version\\), 0\\) FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(version))
+}
+
+func TestMigrateToUp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectMigrationVersion(mock, 2)
+	for _, m := range migrations[2:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(m.up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(m.version, m.name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	current, err := migrateTo(db, latestSchemaVersion(), newTestLogger())
+	if err != nil || current != 2 {
+		t.Errorf("TestMigrateToUp got unexpected result: %d, %v", current, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestMigrateToUp there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMigrateToLegacy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	expectMigrationVersion(mock, 0)
+	mock.ExpectQuery("to_regclass").WillReturnRows(sqlmock.NewRows([]string{"legacy"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(migrationLegacyUp)).WillReturnResult(sqlmock.NewResult(0, 0))
+	for _, m := range migrations[:legacySchemaVersion] {
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(m.version, m.name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+	for _, m := range migrations[legacySchemaVersion:] {
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(m.up)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").WithArgs(m.version, m.name).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+	}
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err = migrateTo(db, latestSchemaVersion(), newTestLogger()); err != nil {
+		t.Errorf("TestMigrateToLegacy got unexpected error: %v", err)
+	}
+
+	// старую базу ниже legacySchemaVersion не трогаем
+	expectMigrationVersion(mock, 0)
+	mock.ExpectQuery("to_regclass").WillReturnRows(sqlmock.NewRows([]string{"legacy"}).AddRow(true))
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err = migrateTo(db, legacySchemaVersion-1, newTestLogger()); err == nil {
+		t.Error("TestMigrateToLegacy expected error for target below legacy version")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestMigrateToLegacy there were unfulfilled expectations: %s", err)
+	}
+}
+
+// legacySchema схема, которую раньше накатывали из sql/games.sql и sql/games_users.sql
+const legacySchema = `
+CREATE EXTENSION IF NOT EXISTS CITEXT;
+
+CREATE TABLE "games"
+(
+	id bigserial not null
+		constraint game_pk
+			primary key,
+	slug CITEXT UNIQUE CONSTRAINT games_slug_check CHECK ( slug ~ '^(\d|\w|-|_)*(\w|-|_)(\d|\w|-|_)*$' ),
+	title CITEXT UNIQUE CONSTRAINT title_empty not null check ( title <> '' ),
+	description TEXT NOT NULL,
+	rules TEXT NOT NULL,
+	code_example TEXT NOT NULL,
+	bot_code TEXT NOT NULL,
+	logo_uuid UUID NOT NULL,
+	background_uuid UUID NOT NULL
+);
+
+CREATE TABLE "users_games"
+(
+	user_id BIGINT NOT NULL,
+	game_id BIGINT NOT NULL REFERENCES games (id) ON DELETE CASCADE,
+	score INTEGER NOT NULL DEFAULT 0,
+	CONSTRAINT users_games_pk PRIMARY KEY (user_id, game_id)
+);
+
+INSERT INTO games (slug, title, description, rules, code_example, bot_code, logo_uuid, background_uuid)
+VALUES ('pong', 'Pong', '', 'do not cheat', 'a=5', 'a=5',
+	'2eb4a823-3a6d-4cba-8767-4d4946890f4f', '2eb4a823-3a6d-4cba-8767-4d4946890f4e');
+INSERT INTO users_games (user_id, game_id, score) VALUES (7, 1, 10);
+`
+
+func TestMigrateToLegacyPostgres(t *testing.T) {
+	db := openEmptyTestPostgres(t)
+	defer db.Close()
+
+	if _, err := db.Exec(legacySchema); err != nil {
+		t.Fatalf("TestMigrateToLegacyPostgres can not create legacy schema: %v", err)
+	}
+	if _, err := migrateTo(db, latestSchemaVersion(), newTestLogger()); err != nil {
+		t.Fatalf("TestMigrateToLegacyPostgres got unexpected error: %v", err)
+	}
+	if err := checkSchemaVersion(db); err != nil {
+		t.Errorf("TestMigrateToLegacyPostgres got unexpected schema: %v", err)
+	}
+
+	// очки, история, профиль и друзья работают на старых данных
+	ctx := context.Background()
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+	oldScore, newScore, err := gs.UpdateUserScore(ctx, "pong", 7, &ScoreChange{Delta: 5, Source: "test"})
+	if err != nil || oldScore != 10 || newScore != 15 {
+		t.Errorf("TestMigrateToLegacyPostgres got %d -> %d, %v, expected 10 -> 15", oldScore, newScore, err)
+	}
+	if _, err = gs.GetUserGames(ctx, 7); err != nil {
+		t.Errorf("TestMigrateToLegacyPostgres got unexpected profile error: %v", err)
+	}
+	if err = gs.FollowUser(ctx, 7, 8); err != nil {
+		t.Errorf("TestMigrateToLegacyPostgres got unexpected follow error: %v", err)
+	}
+}
+
+func TestMigrateToDownFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	last := migrations[len(migrations)-1]
+	expectMigrationVersion(mock, last.version)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(last.down)).WillReturnError(errors.New("locked"))
+	mock.ExpectRollback()
+	// lock отпускаем и при ошибке
+	mock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1);")).WithArgs(migrationsLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	if _, err = migrateTo(db, last.version-1, newTestLogger()); err == nil {
+		t.Error("TestMigrateToDownFailure expected error")
+	}
+
+	if _, err = migrateTo(db, latestSchemaVersion()+1, newTestLogger()); err == nil {
+		t.Error("TestMigrateToDownFailure expected error for unknown version")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestMigrateToDownFailure there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(latestSchemaVersion()))
+	mock.ExpectQuery("FROM schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(latestSchemaVersion() - 1))
+	mock.ExpectQuery("FROM schema_migrations").
+		WillReturnError(errors.New(`relation "schema_migrations" does not exist`))
+
+	if err = checkSchemaVersion(db); err != nil {
+		t.Errorf("TestCheckSchemaVersion got unexpected error: %v", err)
+	}
+	if err = checkSchemaVersion(db); err == nil {
+		t.Error("TestCheckSchemaVersion expected error for old schema")
+	}
+	if err = checkSchemaVersion(db); err == nil {
+		t.Error("TestCheckSchemaVersion expected error for empty database")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("TestCheckSchemaVersion there were unfulfilled expectations: %s", err)
+	}
+}
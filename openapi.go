@@ -0,0 +1,246 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaBuilder строит JSON Schema по типам jmodels так же, как их пишет
+// encoding/json (и easyjson): по тегам json, со встроенными структурами,
+// развёрнутыми в родителя. Структуры складываются в components
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{
+		components: make(map[string]interface{}),
+	}
+}
+
+// schema схема значения типа t
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := b.components[t.Name()]; !ok {
+			// сначала заглушка, чтобы рекурсивные типы не зациклились
+			b.components[t.Name()] = nil
+			b.components[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	panic("openapi: unsupported type " + t.String())
+}
+
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	required := make([]string, 0)
+	b.addFields(t, properties, &required)
+	sort.Strings(required)
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if field.Anonymous && tag == "" {
+			b.addFields(field.Type, properties, required)
+			continue
+		}
+		if field.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		name, opts := tag, ""
+		if comma := strings.Index(tag, ","); comma != -1 {
+			name, opts = tag[:comma], tag[comma+1:]
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// responseSchema схема ответа версии v из примеров; несколько -- oneOf
+func (b *schemaBuilder) responseSchema(v apiVersion, examples []interface{}) map[string]interface{} {
+	if len(examples) == 1 {
+		return b.schema(reflect.TypeOf(adaptResponse(v, examples[0])))
+	}
+
+	oneOf := make([]interface{}, len(examples))
+	for i, example := range examples {
+		oneOf[i] = b.schema(reflect.TypeOf(adaptResponse(v, example)))
+	}
+
+	return map[string]interface{}{"oneOf": oneOf}
+}
+
+// openAPIOperation описание одного роута в версии v
+func (b *schemaBuilder) openAPIOperation(v apiVersion, route *apiRoute) map[string]interface{} {
+	params := make([]interface{}, len(route.params))
+	for i, p := range route.params {
+		params[i] = map[string]interface{}{
+			"name":        p.name,
+			"in":          p.in,
+			"description": p.description,
+			"required":    p.in == "path",
+			"schema":      p.schema,
+		}
+	}
+
+	success := map[string]interface{}{"description": http.StatusText(route.status)}
+	switch {
+	case len(route.contentTypes) != 0:
+		content := make(map[string]interface{})
+		for _, contentType := range route.contentTypes {
+			media := map[string]interface{}{
+				"schema": map[string]interface{}{"type": "string"},
+			}
+			// в OpenAPI 3.0 нет схем для потока JSON объектов, поэтому
+			// схема строки -- в расширении, в форме версии v
+			if contentType == exportContentTypes[ExportNDJSON] && route.rows != nil {
+				media["x-row-schema"] = b.schema(reflect.TypeOf(adaptResponse(v, route.rows)).Elem())
+			}
+			content[contentType] = media
+		}
+		success["content"] = content
+	case len(route.responses) != 0:
+		success["content"] = map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": b.responseSchema(v, route.responses)},
+		}
+	}
+
+	responses := map[string]interface{}{strconv.Itoa(route.status): success}
+	errSchema := b.schema(reflect.TypeOf(&jmodels.APIError{}))
+	errCodes := route.errors
+	if route.role != "" {
+		errCodes = append(append([]int{}, errCodes...),
+			http.StatusUnauthorized, http.StatusForbidden, http.StatusServiceUnavailable)
+	}
+	for _, code := range errCodes {
+		responses[strconv.Itoa(code)] = map[string]interface{}{
+			"description": http.StatusText(code),
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": errSchema},
+			},
+		}
+	}
+
+	operation := map[string]interface{}{
+		"summary":   route.summary,
+		"responses": responses,
+	}
+	if route.request != nil {
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": b.schema(reflect.TypeOf(route.request))},
+			},
+		}
+	}
+	if len(params) != 0 {
+		operation["parameters"] = params
+	}
+	if v.deprecated() {
+		operation["deprecated"] = true
+	}
+	if route.role != "" {
+		operation["security"] = []interface{}{
+			map[string]interface{}{"sessionCookie": []string{}},
+			map[string]interface{}{"bearerAuth": []string{}},
+		}
+		description := "Нужна роль " + route.role
+		if route.owner != "" {
+			description += "; не admin может действовать только от своего " + route.owner
+		}
+		operation["description"] = description
+	}
+
+	return operation
+}
+
+// buildOpenAPI документ OpenAPI 3 для роутов версии v
+func buildOpenAPI(v apiVersion, routes []*apiRoute) map[string]interface{} {
+	b := newSchemaBuilder()
+	paths := make(map[string]interface{})
+	for _, route := range routes {
+		item, ok := paths[route.path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[route.path] = item
+		}
+		item[strings.ToLower(route.method)] = b.openAPIOperation(v, route)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "warscript-games",
+			"version": strconv.Itoa(int(v)) + ".0.0",
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": v.prefix()},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": b.components,
+			"securitySchemes": map[string]interface{}{
+				"sessionCookie": map[string]interface{}{"type": "apiKey", "in": "cookie", "name": sessionCookie},
+				"bearerAuth":    map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// openAPIHandler отдаёт документ, посчитанный один раз при старте
+func openAPIHandler(spec map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteApplicationJSON(w, http.StatusOK, spec)
+	}
+}
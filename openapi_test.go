@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// validateSchema проверяет, что value (результат json.Unmarshal в interface{})
+// подходит под schema: типы, обязательные и лишние поля, $ref и oneOf
+func validateSchema(spec, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/components/schemas/")
+		component, ok := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})[name]
+		if !ok {
+			return errors.Errorf("%s: unknown schema %s", path, ref)
+		}
+		return validateSchema(spec, component.(map[string]interface{}), value, path)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		matched := 0
+		for _, variant := range oneOf {
+			if validateSchema(spec, variant.(map[string]interface{}), value, path) == nil {
+				matched++
+			}
+		}
+		if matched == 0 {
+			return errors.Errorf("%s: matches none of oneOf", path)
+		}
+		return nil
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("%s: expected object, got %T", path, value)
+		}
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				if _, ok := obj[name.(string)]; !ok {
+					return errors.Errorf("%s: missing required field %s", path, name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for name, fieldValue := range obj {
+			fieldSchema, ok := properties[name]
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+					fieldSchema = additional
+				} else {
+					return errors.Errorf("%s: unexpected field %s", path, name)
+				}
+			}
+			if err := validateSchema(spec, fieldSchema.(map[string]interface{}), fieldValue, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return errors.Errorf("%s: expected array, got %T", path, value)
+		}
+		for i, item := range arr {
+			if err := validateSchema(spec, schema["items"].(map[string]interface{}), item, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return errors.Errorf("%s: expected string, got %T", path, value)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return errors.Errorf("%s: expected date-time, got %q", path, str)
+			}
+		}
+	case "integer":
+		num, ok := value.(float64)
+		if !ok || num != math.Trunc(num) {
+			return errors.Errorf("%s: expected integer, got %v", path, value)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return errors.Errorf("%s: expected number, got %T", path, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return errors.Errorf("%s: expected boolean, got %T", path, value)
+		}
+	default:
+		return errors.Errorf("%s: unsupported schema %v", path, schema)
+	}
+
+	return nil
+}
+
+// loadOpenAPI документ в том виде, в каком его получит клиент
+func loadOpenAPI(t *testing.T, baseURL string, v apiVersion) map[string]interface{} {
+	resp, err := http.Get(baseURL + v.prefix() + "/openapi.json")
+	if err != nil {
+		t.Fatalf("can not get openapi.json: %v", err)
+	}
+	defer resp.Body.Close()
+
+	spec := make(map[string]interface{})
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		t.Fatalf("can not decode openapi.json: %v", err)
+	}
+
+	return spec
+}
+
+func TestOpenAPIDrift(t *testing.T) {
+	srv := initTests()
+	ts := httptest.NewServer(srv.httpHandler(newHealthChecker(&pingerTest{}, nil)))
+	defer ts.Close()
+
+	for _, v := range apiVersions {
+		testOpenAPIDrift(t, ts.URL, v)
+	}
+}
+
+// testOpenAPIDrift ответы версии v сходятся с её же документом
+func testOpenAPIDrift(t *testing.T, baseURL string, v apiVersion) {
+	spec := loadOpenAPI(t, baseURL, v)
+
+	// хотя бы по одному запросу на каждый роут, с непустыми ответами
+	cases := []struct {
+		method   string
+		pattern  string
+		endpoint string
+		body     string
+	}{
+		{"GET", "/leaderboard", "/leaderboard?formula=scores", ""},
+		{"GET", "/leaderboard", "/leaderboard?limit=0", ""},
+		{"GET", "/users/{user_id}/games", "/users/1/games", ""},
+		{"GET", "/users/{user_id}/following", "/users/1/following", ""},
+		{"PUT", "/users/{user_id}/following/{followee_id}", "/users/1/following/2", ""},
+		{"DELETE", "/users/{user_id}/following/{followee_id}", "/users/1/following/2", ""},
+		{"GET", "/games", "/games", ""},
+		{"GET", "/games/{game_slug}", "/games/pong", ""},
+		{"GET", "/games/{game_slug}", "/games/ping", ""},
+		{"GET", "/games/{game_slug}/leaderboard", "/games/pong/leaderboard", ""},
+		{"GET", "/games/{game_slug}/leaderboard", "/games/pong/leaderboard?users=5,7", ""},
+		{"GET", "/games/{game_slug}/leaderboard", "/games/pong/leaderboard?window=day", ""},
+		{"GET", "/games/{game_slug}/leaderboard/count", "/games/pong/leaderboard/count", ""},
+		{"GET", "/games/{game_slug}/leaderboard/stats", "/games/pong/leaderboard/stats?buckets=2", ""},
+		{"GET", "/games/{game_slug}/leaderboard/export", "/games/pong/leaderboard/export?format=ndjson", ""},
+		{"GET", "/admin/games/{game_slug}/users/{user_id}/score-events", "/admin/games/pong/users/1/score-events", ""},
+		{"PATCH", "/games/{game_slug}", "/games/pong", `{"title":"Pong 2"}`},
+		{"PATCH", "/games/{game_slug}", "/games/pong", `{"logo_uuid":"nope"}`},
+		{"PUT", "/games/{game_slug}/users/{user_id}/hidden", "/games/pong/users/2/hidden", ""},
+		{"DELETE", "/games/{game_slug}/users/{user_id}/hidden", "/games/pong/users/2/hidden", ""},
+		{"PUT", "/admin/users/{user_id}/grants/{role}", "/admin/users/3/grants/author?game=pong", ""},
+		{"GET", "/admin/users/{user_id}/grants", "/admin/users/3/grants", ""},
+		{"DELETE", "/admin/users/{user_id}/grants/{role}", "/admin/users/3/grants/author?game=pong", ""},
+	}
+
+	covered := make(map[string]bool)
+	for i, c := range cases {
+		covered[c.method+" "+c.pattern] = true
+
+		req, _ := http.NewRequest(c.method, baseURL+v.prefix()+c.endpoint, strings.NewReader(c.body))
+		req.AddCookie(&http.Cookie{Name: sessionCookie, Value: testAdminToken})
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("[%d] TestOpenAPIDrift got unexpected error: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		operation, ok := spec["paths"].(map[string]interface{})[c.pattern].(map[string]interface{})[strings.ToLower(c.method)].(map[string]interface{})
+		if !ok {
+			t.Errorf("[%d] TestOpenAPIDrift %s %s %s is not documented", i, v.prefix(), c.method, c.pattern)
+			continue
+		}
+		response, ok := operation["responses"].(map[string]interface{})[strconv.Itoa(resp.StatusCode)].(map[string]interface{})
+		if !ok {
+			t.Errorf("[%d] TestOpenAPIDrift %s %s %s responded undocumented %d", i, v.prefix(), c.method, c.endpoint, resp.StatusCode)
+			continue
+		}
+
+		content, ok := response["content"].(map[string]interface{})
+		if !ok {
+			if len(body) != 0 {
+				t.Errorf("[%d] TestOpenAPIDrift %s %s %s responded body %s, documented none", i, v.prefix(), c.method, c.endpoint, body)
+			}
+			continue
+		}
+		media, ok := content[resp.Header.Get("Content-Type")].(map[string]interface{})
+		if !ok {
+			t.Errorf("[%d] TestOpenAPIDrift %s %s %s responded undocumented Content-Type %s",
+				i, v.prefix(), c.method, c.endpoint, resp.Header.Get("Content-Type"))
+			continue
+		}
+		if rowSchema, ok := media["x-row-schema"].(map[string]interface{}); ok {
+			for j, line := range strings.Split(strings.TrimSuffix(string(body), "\n"), "\n") {
+				var value interface{}
+				if err := json.Unmarshal([]byte(line), &value); err != nil {
+					t.Errorf("[%d] TestOpenAPIDrift %s %s %s responded invalid row %d: %v", i, v.prefix(), c.method, c.endpoint, j, err)
+					continue
+				}
+				if err := validateSchema(spec, rowSchema, value, "row"); err != nil {
+					t.Errorf("[%d] TestOpenAPIDrift %s %s %s row %d drifted from schema: %v", i, v.prefix(), c.method, c.endpoint, j, err)
+				}
+			}
+			continue
+		}
+		if resp.Header.Get("Content-Type") != "application/json" {
+			continue
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err != nil {
+			t.Errorf("[%d] TestOpenAPIDrift %s %s %s responded invalid json: %v", i, v.prefix(), c.method, c.endpoint, err)
+			continue
+		}
+		if err := validateSchema(spec, media["schema"].(map[string]interface{}), value, "body"); err != nil {
+			t.Errorf("[%d] TestOpenAPIDrift %s %s %s drifted from schema: %v", i, v.prefix(), c.method, c.endpoint, err)
+		}
+	}
+
+	for pattern, item := range spec["paths"].(map[string]interface{}) {
+		for method := range item.(map[string]interface{}) {
+			if !covered[strings.ToUpper(method)+" "+pattern] {
+				t.Errorf("TestOpenAPIDrift %s has no case for %s %s", v.prefix(), strings.ToUpper(method), pattern)
+			}
+		}
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	spec := buildOpenAPI(apiV1, initTests().apiRoutes())
+	gameSchema := map[string]interface{}{"$ref": "#/components/schemas/Game"}
+
+	cases := []struct {
+		body        string
+		expectedErr bool
+	}{
+		{body: `{"slug":"pong","title":"Pong","background_uuid":""}`},
+		{body: `{"slug":"pong","title":"Pong"}`, expectedErr: true},
+		{body: `{"slug":"pong","title":"Pong","background_uuid":"","logo_uuid":""}`, expectedErr: true},
+		{body: `{"slug":1,"title":"Pong","background_uuid":""}`, expectedErr: true},
+	}
+
+	// документ из buildOpenAPI и из ответа сервера должен выглядеть одинаково
+	raw, _ := json.Marshal(spec)
+	decoded := make(map[string]interface{})
+	//nolint: errcheck
+	json.Unmarshal(raw, &decoded)
+
+	for i, c := range cases {
+		var value interface{}
+		//nolint: errcheck
+		json.Unmarshal([]byte(c.body), &value)
+		err := validateSchema(decoded, gameSchema, value, "body")
+		if (err != nil) != c.expectedErr {
+			t.Errorf("[%d] TestValidateSchema got %v, expected error: %v", i, err, c.expectedErr)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// OTLP/JSON: https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+// ID в hex, 64-битные числа строками
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	// Code 0 -- не задан, 2 -- ошибка
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTraces struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// otlpExporter отправляет спаны POST'ом на OTLP/HTTP коллектор,
+// обычно http://collector:4318/v1/traces
+type otlpExporter struct {
+	endpoint string
+	service  string
+	client   *http.Client
+}
+
+func newOTLPExporter(endpoint, service string) *otlpExporter {
+	return &otlpExporter{
+		endpoint: endpoint,
+		service:  service,
+		client:   &http.Client{},
+	}
+}
+
+func otlpAttr(key string, value interface{}) otlpAttribute {
+	attr := otlpAttribute{Key: key}
+	switch v := value.(type) {
+	case int:
+		i := strconv.Itoa(v)
+		attr.Value.IntValue = &i
+	case string:
+		attr.Value.StringValue = &v
+	}
+
+	return attr
+}
+
+// otlpPayload тело запроса в коллектор
+func (e *otlpExporter) otlpPayload(spans []*span) *otlpTraces {
+	converted := make([]otlpSpan, len(spans))
+	for i, s := range spans {
+		converted[i] = otlpSpan{
+			TraceID:           hex.EncodeToString(s.sc.traceID[:]),
+			SpanID:            hex.EncodeToString(s.sc.spanID[:]),
+			Name:              s.name,
+			Kind:              int(s.kind),
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		}
+		if s.parentID != [8]byte{} {
+			converted[i].ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, a := range s.attrs {
+			converted[i].Attributes = append(converted[i].Attributes, otlpAttr(a.key, a.value))
+		}
+		if s.errMsg != "" {
+			converted[i].Status = otlpStatus{Code: 2, Message: s.errMsg}
+		}
+	}
+
+	return &otlpTraces{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{otlpAttr("service.name", e.service)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: e.service},
+				Spans: converted,
+			}},
+		}},
+	}
+}
+
+// Export отправляет спаны одним запросом
+func (e *otlpExporter) Export(ctx context.Context, spans []*span) error {
+	body, err := json.Marshal(e.otlpPayload(spans))
+	if err != nil {
+		return errors.Wrap(err, "can not marshal spans")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "can not create collector request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "can not send spans to collector")
+	}
+	defer resp.Body.Close()
+	//nolint: errcheck
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("collector responded %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/gorilla/mux"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultLeaderboardLimit размер страницы leaderboard по умолчанию
+	defaultLeaderboardLimit = 5
+	// defaultEventsLimit размер страницы истории очков по умолчанию
+	defaultEventsLimit = 50
+	// maxLimit больше за раз не отдаём
+	maxLimit = 100
+	// maxOffset дальше листать незачем, а postgres на огромном offset
+	// всё равно перебирает все строки до него
+	maxOffset = 100000
+)
+
+// errOutOfRange число, но за допустимыми границами
+var errOutOfRange = errors.New("out_of_range")
+
+// queryParams разбор параметров пути и query. Ошибки копятся по полям,
+// чтобы ответить одним 400 обо всех сразу:
+//
+//	params := newQueryParams(r)
+//	limit := params.Limit(defaultLeaderboardLimit)
+//	offset := params.Offset()
+//	if validErr := params.Err(); validErr != nil {
+//		errWriter.WriteValidationError(validErr)
+//		return
+//	}
+type queryParams struct {
+	query    url.Values
+	vars     map[string]string
+	validErr utils.ValidationError
+}
+
+func newQueryParams(r *http.Request) *queryParams {
+	return &queryParams{
+		query:    r.URL.Query(),
+		vars:     mux.Vars(r),
+		validErr: utils.ValidationError{},
+	}
+}
+
+// fail запоминает первую ошибку поля
+func (p *queryParams) fail(field string, err error) {
+	if _, ok := p.validErr[field]; !ok {
+		p.validErr[field] = err.Error()
+	}
+}
+
+// Err ошибки всех разобранных параметров или nil
+func (p *queryParams) Err() *utils.ValidationError {
+	if len(p.validErr) == 0 {
+		return nil
+	}
+
+	return &p.validErr
+}
+
+// String необязательный параметр ?name=
+func (p *queryParams) String(name string) string {
+	return p.query.Get(name)
+}
+
+// Int необязательный параметр ?name= в границах [min, max]; без него def
+func (p *queryParams) Int(name string, def, min, max int) int {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		p.fail(name, utils.ErrInvalid)
+		return def
+	}
+	if v < min || v > max {
+		p.fail(name, errOutOfRange)
+		return def
+	}
+
+	return v
+}
+
+// Limit ?limit= от 1 до maxLimit
+func (p *queryParams) Limit(def int) int {
+	return p.Int("limit", def, 1, maxLimit)
+}
+
+// Offset ?offset= от 0 до maxOffset
+func (p *queryParams) Offset() int {
+	return p.Int("offset", 0, 0, maxOffset)
+}
+
+func parseID(raw string) (int64, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, utils.ErrInvalid
+	}
+
+	return id, nil
+}
+
+// PathID положительный ID из пути /users/{name}
+func (p *queryParams) PathID(name string) int64 {
+	id, err := parseID(p.vars[name])
+	if err != nil {
+		p.fail(name, err)
+	}
+
+	return id
+}
+
+// ID необязательный положительный ID ?name=; без него 0
+func (p *queryParams) ID(name string) int64 {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return 0
+	}
+
+	id, err := parseID(raw)
+	if err != nil {
+		p.fail(name, err)
+	}
+
+	return id
+}
+
+// IDs необязательный список ID через запятую ?name=1,2,3
+func (p *queryParams) IDs(name string) []int64 {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxLeaderboardUsers {
+		p.fail(name, errOutOfRange)
+		return nil
+	}
+
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := parseID(part)
+		if err != nil {
+			p.fail(name, err)
+			return nil
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+// Date необязательная дата ?name=YYYY-MM-DD в loc; без неё def
+func (p *queryParams) Date(name string, def time.Time, loc *time.Location) time.Time {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return def
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		p.fail(name, utils.ErrInvalid)
+		return def
+	}
+
+	return t
+}
+
+// OneOf необязательный параметр ?name= из допустимых значений valid; без него def
+func (p *queryParams) OneOf(name, def string, valid func(string) bool) string {
+	raw := p.query.Get(name)
+	if raw == "" {
+		return def
+	}
+	if !valid(raw) {
+		p.fail(name, utils.ErrInvalid)
+		return def
+	}
+
+	return raw
+}
+
+// pageParams limit и offset из gRPC запроса в тех же границах, что и у http;
+// limit 0 -- не задан
+func pageParams(limit, offset int32, def int) (int, int, error) {
+	validErr := utils.ValidationError{}
+	if limit == 0 {
+		limit = int32(def)
+	}
+	if limit < 1 || limit > maxLimit {
+		validErr["limit"] = errOutOfRange.Error()
+	}
+	if offset < 0 || offset > maxOffset {
+		validErr["offset"] = errOutOfRange.Error()
+	}
+
+	if len(validErr) != 0 {
+		return 0, 0, &validErr
+	}
+
+	return int(limit), int(offset), nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/pkg/errors"
+)
+
+// роли, которые выдаются через grants. admin и moderator бывают на все
+// игры, moderator и author -- на одну
+const (
+	roleModerator = "moderator"
+	roleAuthor    = "author"
+)
+
+// permission действие, на которое нужна роль
+type permission string
+
+const (
+	// permEditGame менять описание игры
+	permEditGame permission = "games.edit"
+	// permModerateScores скрывать очки игроков и править их вручную
+	permModerateScores permission = "scores.moderate"
+)
+
+// rolePermissions что можно каждой роли; admin можно всё
+var rolePermissions = map[string][]permission{
+	roleModerator: {permModerateScores},
+	roleAuthor:    {permEditGame},
+}
+
+// sessionMetadataKey метаданные вызова gRPC с сессией пользователя,
+// от имени которого он сделан
+const sessionMetadataKey = "x-session-token"
+
+// serviceMetadataKey метаданные вызова gRPC с токеном сервиса из ServiceTokens
+const serviceMetadataKey = "x-service-token"
+
+// validateGrant можно ли выдать role на игру slug (пустой -- на все игры)
+func validateGrant(role, slug string) *utils.ValidationError {
+	switch {
+	case role != roleAdmin && role != roleModerator && role != roleAuthor:
+		return &utils.ValidationError{"role": utils.ErrInvalid.Error()}
+	case role == roleAdmin && slug != "":
+		return &utils.ValidationError{"game": utils.ErrInvalid.Error()}
+	case role == roleAuthor && slug == "":
+		return &utils.ValidationError{"game": utils.ErrRequired.Error()}
+	}
+
+	return nil
+}
+
+// allows можно ли пользователю perm в игре slug. Роли на все игры
+// действуют в любой игре
+func (u *authUser) allows(perm permission, slug string) bool {
+	if u == nil {
+		return false
+	}
+	if u.can(roleAdmin) {
+		return true
+	}
+
+	for _, g := range u.grants {
+		if g.GameSlug != "" && !strings.EqualFold(g.GameSlug, slug) {
+			continue
+		}
+		for _, p := range rolePermissions[g.Role] {
+			if p == perm {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// authorize errForbidden, если пользователю запроса нельзя perm в игре slug,
+// и errUnauthorized, если запрос без пользователя
+func authorize(ctx context.Context, perm permission, slug string) error {
+	user := userFromContext(ctx)
+	if user == nil {
+		return errors.Wrapf(errUnauthorized, "%s requires a user", perm)
+	}
+	if !user.allows(perm, slug) {
+		return errors.Wrapf(errForbidden, "user %d has no %s in %q", user.ID, perm, slug)
+	}
+
+	return nil
+}
+
+// grpcService вызов gRPC сделал сервис с токеном из ServiceTokens
+func (s *Server) grpcService(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(serviceMetadataKey)
+	if len(tokens) == 0 || tokens[0] == "" {
+		return false
+	}
+
+	for _, token := range s.cfg.ServiceTokens {
+		if subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// grpcUser пользователь, от имени которого сделан вызов gRPC;
+// nil, если сессии в метаданных нет
+func (s *Server) grpcUser(ctx context.Context) (*authUser, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(sessionMetadataKey)
+	if len(tokens) == 0 || tokens[0] == "" {
+		return nil, nil
+	}
+
+	return s.authenticate(ctx, tokens[0])
+}
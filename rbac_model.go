@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+// GrantModel модель для таблицы grants
+type GrantModel struct {
+	ID     int64
+	UserID int64
+	// GameSlug пустой -- роль на все игры
+	GameSlug  string
+	Role      string
+	GrantedBy int64
+	CreatedAt time.Time
+}
+
+// GetUserGrants все роли пользователя, в порядке выдачи
+func (gs *AccessObject) GetUserGrants(ctx context.Context, userID int64) ([]*GrantModel, error) {
+	ctx = withQueryMethod(ctx, "GetUserGrants")
+
+	rows, err := gs.db.QueryContext(ctx, `SELECT gr.id, gr.user_id, coalesce(g.slug, ''), gr.role,
+					gr.granted_by, gr.created_at
+					FROM grants gr LEFT JOIN games g ON g.id = gr.game_id
+					WHERE gr.user_id = $1 ORDER BY gr.id;`, userID)
+	if err != nil {
+		return nil, internalError(ctx, "get user grants error: %v", err)
+	}
+	defer rows.Close()
+
+	grants := make([]*GrantModel, 0)
+	for rows.Next() {
+		g := &GrantModel{}
+		if err = rows.Scan(&g.ID, &g.UserID, &g.GameSlug, &g.Role, &g.GrantedBy, &g.CreatedAt); err != nil {
+			return nil, internalError(ctx, "get user grants scan error: %v", err)
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, nil
+}
+
+// AddGrant выдаёт роль g.Role на игру g.GameSlug (или на все игры).
+// Возвращает false, если такая роль уже была
+func (gs *AccessObject) AddGrant(ctx context.Context, g *GrantModel) (bool, error) {
+	ctx = withQueryMethod(ctx, "AddGrant")
+
+	var gameID sql.NullInt64
+	if g.GameSlug != "" {
+		game, err := gs.getGameImpl(ctx, gs.db, "slug", g.GameSlug)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return false, utils.ErrNotExists
+			}
+
+			return false, internalError(ctx, "AddGrant can not get game by slug: %v", err)
+		}
+		gameID = sql.NullInt64{Int64: game.ID, Valid: true}
+	}
+
+	err := gs.db.QueryRowContext(ctx, `INSERT INTO grants (user_id, game_id, role, granted_by)
+					VALUES ($1, $2, $3, $4)
+					ON CONFLICT DO NOTHING
+					RETURNING id, created_at;`, g.UserID, gameID, g.Role, g.GrantedBy).Scan(&g.ID, &g.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, internalError(ctx, "can not add grant: %v", err)
+	}
+
+	return true, nil
+}
+
+// DeleteGrant забирает роль role на игру slug (пустой -- на все игры)
+func (gs *AccessObject) DeleteGrant(ctx context.Context, userID int64, slug, role string) error {
+	ctx = withQueryMethod(ctx, "DeleteGrant")
+
+	var res sql.Result
+	var err error
+	if slug == "" {
+		res, err = gs.db.ExecContext(ctx, `DELETE FROM grants
+					WHERE user_id = $1 AND role = $2 AND game_id IS NULL;`, userID, role)
+	} else {
+		res, err = gs.db.ExecContext(ctx, `DELETE FROM grants
+					WHERE user_id = $1 AND role = $2 AND game_id = (SELECT id FROM games WHERE slug = $3);`,
+			userID, role, slug)
+	}
+	if err != nil {
+		return internalError(ctx, "delete grant error: %v", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return internalError(ctx, "delete grant rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return utils.ErrNotExists
+	}
+
+	return nil
+}
+
+// SetScoreHidden скрывает очки пользователя в игре из всего, что видят
+// игроки, или возвращает их обратно. Сами очки и история не меняются
+func (gs *AccessObject) SetScoreHidden(ctx context.Context, slug string, userID int64, hidden bool) error {
+	ctx = withQueryMethod(ctx, "SetScoreHidden")
+
+	res, err := gs.db.ExecContext(ctx, `UPDATE users_games SET hidden = $3
+					WHERE user_id = $2 AND game_id = (SELECT id FROM games WHERE slug = $1);`,
+		slug, userID, hidden)
+	if err != nil {
+		return internalError(ctx, "set score hidden error: %v", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return internalError(ctx, "set score hidden rows affected error: %v", err)
+	}
+
+	if affected == 0 {
+		return utils.ErrNotExists
+	}
+
+	return nil
+}
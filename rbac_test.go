@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/HotCodeGroup/warscript-games/gmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/pkg/errors"
+)
+
+func TestAuthUserAllows(t *testing.T) {
+	author := &authUser{ID: 2, Role: rolePlayer, grants: []*GrantModel{{GameSlug: "pong", Role: roleAuthor}}}
+	moderator := &authUser{ID: 3, Role: rolePlayer, grants: []*GrantModel{{Role: roleModerator}}}
+	gameModerator := &authUser{ID: 4, Role: rolePlayer, grants: []*GrantModel{{GameSlug: "pong", Role: roleModerator}}}
+	admin := &authUser{ID: 1, Role: roleAdmin}
+
+	cases := []struct {
+		user     *authUser
+		perm     permission
+		slug     string
+		expected bool
+	}{
+		{user: author, perm: permEditGame, slug: "pong", expected: true},
+		{user: author, perm: permEditGame, slug: "PONG", expected: true},
+		{user: author, perm: permEditGame, slug: "ping", expected: false},
+		{user: author, perm: permModerateScores, slug: "pong", expected: false},
+		{user: moderator, perm: permModerateScores, slug: "ping", expected: true},
+		{user: moderator, perm: permEditGame, slug: "ping", expected: false},
+		{user: gameModerator, perm: permModerateScores, slug: "pong", expected: true},
+		{user: gameModerator, perm: permModerateScores, slug: "ping", expected: false},
+		{user: admin, perm: permEditGame, slug: "ping", expected: true},
+		{user: admin, perm: permModerateScores, slug: "ping", expected: true},
+		{user: &authUser{ID: 5, Role: rolePlayer}, perm: permEditGame, slug: "pong", expected: false},
+		{user: nil, perm: permEditGame, slug: "pong", expected: false},
+	}
+
+	for i, c := range cases {
+		if got := c.user.allows(c.perm, c.slug); got != c.expected {
+			t.Errorf("[%d] TestAuthUserAllows %+v %s in %s got %v, expected %v", i, c.user, c.perm, c.slug, got, c.expected)
+		}
+	}
+
+	if err := authorize(context.Background(), permEditGame, "pong"); errors.Cause(err) != errUnauthorized {
+		t.Errorf("TestAuthUserAllows got unexpected error: %v, expected: %v", err, errUnauthorized)
+	}
+	if err := authorize(withUser(context.Background(), author), permEditGame, "ping"); errors.Cause(err) != errForbidden {
+		t.Errorf("TestAuthUserAllows got unexpected error: %v, expected: %v", err, errForbidden)
+	}
+}
+
+func TestValidateGrant(t *testing.T) {
+	cases := []struct {
+		role        string
+		slug        string
+		expectedErr bool
+	}{
+		{role: roleAdmin},
+		{role: roleAdmin, slug: "pong", expectedErr: true},
+		{role: roleModerator},
+		{role: roleModerator, slug: "pong"},
+		{role: roleAuthor, expectedErr: true},
+		{role: roleAuthor, slug: "pong"},
+		{role: rolePlayer, expectedErr: true},
+	}
+
+	for i, c := range cases {
+		if err := validateGrant(c.role, c.slug); (err != nil) != c.expectedErr {
+			t.Errorf("[%d] TestValidateGrant got %v, expected error: %v", i, err, c.expectedErr)
+		}
+	}
+}
+
+func TestRBACFlow(t *testing.T) {
+	ts := httptest.NewServer(initTests().httpHandler(newHealthChecker(&pingerTest{}, nil)))
+	defer ts.Close()
+
+	// шаги по порядку: роли, выданные admin, сразу действуют на player
+	steps := []struct {
+		method       string
+		path         string
+		token        string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		{method: "PATCH", path: "/v1/games/pong", token: testPlayerToken, body: `{"title":"Pong 2"}`,
+			expectedCode: http.StatusForbidden, expectedBody: `{"message":"forbidden"}`},
+		{method: "PUT", path: "/v1/admin/users/2/grants/author?game=pong", token: testPlayerToken,
+			expectedCode: http.StatusForbidden},
+		{method: "PUT", path: "/v1/admin/users/2/grants/author?game=pong", token: testAdminToken,
+			expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v1/admin/users/2/grants/author?game=ping", token: testAdminToken,
+			expectedCode: http.StatusNotFound},
+		{method: "PUT", path: "/v1/admin/users/2/grants/author", token: testAdminToken,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid_params","fields":[{"field":"game","error":"required"}]}`},
+		{method: "PATCH", path: "/v1/games/pong", token: testPlayerToken, body: `{"title":"Pong 2"}`,
+			expectedCode: http.StatusOK},
+		{method: "GET", path: "/v1/games/pong", expectedCode: http.StatusOK},
+		{method: "PATCH", path: "/v1/games/pong", token: testPlayerToken, body: `{"title":" ","logo_uuid":"x"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid_params","fields":[{"field":"logo_uuid","error":"invalid"},` +
+				`{"field":"title","error":"required"}]}`},
+		{method: "PATCH", path: "/v1/games/pong", token: testPlayerToken, body: `{"title":`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid_params","fields":[{"field":"body","error":"invalid"}]}`},
+		{method: "PUT", path: "/v1/games/pong/users/1/hidden", token: testPlayerToken,
+			expectedCode: http.StatusForbidden},
+		{method: "PUT", path: "/v1/admin/users/2/grants/moderator", token: testAdminToken,
+			expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v1/games/pong/users/1/hidden", token: testPlayerToken,
+			expectedCode: http.StatusNoContent},
+		{method: "PUT", path: "/v1/games/pong/users/9/hidden", token: testPlayerToken,
+			expectedCode: http.StatusNotFound},
+		{method: "DELETE", path: "/v1/games/pong/users/1/hidden", token: testPlayerToken,
+			expectedCode: http.StatusNoContent},
+		{method: "GET", path: "/v1/admin/users/2/grants", token: testPlayerToken,
+			expectedCode: http.StatusForbidden},
+		{method: "GET", path: "/v1/admin/users/2/grants", token: testAdminToken, expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"user_id":2,"game_slug":"pong","role":"author","granted_by":1,` +
+				`"created_at":"2019-05-01T12:00:00Z"},` +
+				`{"id":2,"user_id":2,"role":"moderator","granted_by":1,"created_at":"2019-05-01T12:00:00Z"}]`},
+		{method: "DELETE", path: "/v1/admin/users/2/grants/moderator", token: testAdminToken,
+			expectedCode: http.StatusNoContent},
+		{method: "DELETE", path: "/v1/admin/users/2/grants/moderator", token: testAdminToken,
+			expectedCode: http.StatusNotFound},
+		{method: "PUT", path: "/v1/games/pong/users/1/hidden", token: testPlayerToken,
+			expectedCode: http.StatusForbidden},
+		// admin из grants, а не из конфига
+		{method: "PUT", path: "/v1/admin/users/2/grants/admin", token: testAdminToken,
+			expectedCode: http.StatusNoContent},
+		{method: "DELETE", path: "/v1/admin/users/2/grants/author?game=pong", token: testPlayerToken,
+			expectedCode: http.StatusNoContent},
+	}
+
+	for i, c := range steps {
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, strings.NewReader(c.body))
+		if c.token != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookie, Value: c.token})
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("[%d] TestRBACFlow got unexpected error: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != c.expectedCode {
+			t.Fatalf("[%d] TestRBACFlow %s %s got code %d %s, expected %d",
+				i, c.method, c.path, resp.StatusCode, body, c.expectedCode)
+		}
+		if c.expectedBody != "" && string(body) != c.expectedBody {
+			t.Errorf("[%d] TestRBACFlow %s %s got body %s, expected %s", i, c.method, c.path, body, c.expectedBody)
+		}
+	}
+}
+
+func TestUpdateScoreRBAC(t *testing.T) {
+	games := &gameTest{
+		games: map[string]*GameModel{
+			"pong": {ID: 1, Slug: "pong"},
+		},
+	}
+	m := newTestServer(games).GamesManager()
+	update := &gmodels.ScoreUpdate{Slug: "pong", UserID: 1, Delta: 5, Source: "moderation"}
+	withSession := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(sessionMetadataKey, token))
+	}
+	withService := func(token string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(serviceMetadataKey, token))
+	}
+
+	cases := []struct {
+		ctx          context.Context
+		expectedCode codes.Code
+	}{
+		// без сессии и токена сервиса открытого пути нет
+		{ctx: context.Background(), expectedCode: codes.Unauthenticated},
+		{ctx: withService(testServiceToken), expectedCode: codes.OK},
+		{ctx: withService("guessed"), expectedCode: codes.Unauthenticated},
+		{ctx: withSession(testPlayerToken), expectedCode: codes.PermissionDenied},
+		{ctx: withSession(testAdminToken), expectedCode: codes.OK},
+		{ctx: withSession("stolen"), expectedCode: codes.Unauthenticated},
+	}
+
+	for i, c := range cases {
+		_, err := m.UpdateScore(c.ctx, update)
+		if code := status.Code(grpcStatusError(update, err)); code != c.expectedCode {
+			t.Errorf("[%d] TestUpdateScoreRBAC got %v (%v), expected %v", i, code, err, c.expectedCode)
+		}
+	}
+
+	games.grants = []*GrantModel{{UserID: 2, GameSlug: "pong", Role: roleModerator}}
+	if _, err := m.UpdateScore(withSession(testPlayerToken), update); err != nil {
+		t.Errorf("TestUpdateScoreRBAC got unexpected error for moderator: %v", err)
+	}
+
+	games.SetNextFail(utils.ErrInternal)
+	_, err := m.UpdateScore(withSession(testPlayerToken), update)
+	if code := status.Code(grpcStatusError(update, err)); code != codes.Internal {
+		t.Errorf("TestUpdateScoreRBAC got %v (%v) on grants error, expected %v", code, err, codes.Internal)
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+)
+
+// apiParam параметр пути или query в описании роута
+type apiParam struct {
+	name        string
+	in          string
+	description string
+	schema      map[string]interface{}
+}
+
+func pathSlugParam() apiParam {
+	return apiParam{name: "game_slug", in: "path", description: "slug игры",
+		schema: map[string]interface{}{"type": "string"}}
+}
+
+func pathIDParam(name, description string) apiParam {
+	return apiParam{name: name, in: "path", description: description,
+		schema: map[string]interface{}{"type": "integer", "format": "int64", "minimum": 1}}
+}
+
+func queryIDParam(name, description string) apiParam {
+	return apiParam{name: name, in: "query", description: description,
+		schema: map[string]interface{}{"type": "integer", "format": "int64", "minimum": 1}}
+}
+
+func queryIntParam(name, description string, def, min, max int) apiParam {
+	return apiParam{name: name, in: "query", description: description,
+		schema: map[string]interface{}{"type": "integer", "default": def, "minimum": min, "maximum": max}}
+}
+
+func queryEnumParam(name, description, def string, values ...string) apiParam {
+	schema := map[string]interface{}{"type": "string", "enum": values}
+	if def != "" {
+		schema["default"] = def
+	}
+
+	return apiParam{name: name, in: "query", description: description, schema: schema}
+}
+
+func queryStringParam(name, description, format string) apiParam {
+	schema := map[string]interface{}{"type": "string"}
+	if format != "" {
+		schema["format"] = format
+	}
+
+	return apiParam{name: name, in: "query", description: description, schema: schema}
+}
+
+func pathRoleParam() apiParam {
+	return apiParam{name: "role", in: "path", description: "роль",
+		schema: map[string]interface{}{"type": "string", "enum": []string{roleAdmin, roleModerator, roleAuthor}}}
+}
+
+func pageParamsDescription(defLimit int) []apiParam {
+	return []apiParam{
+		queryIntParam("limit", "размер страницы", defLimit, 1, maxLimit),
+		queryIntParam("offset", "сколько пропустить", 0, 0, maxOffset),
+	}
+}
+
+// apiRoute роут http api: по этому описанию и регистрируются хэндлеры,
+// и строится OpenAPI, так что одно без другого не поменять
+type apiRoute struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+	summary string
+	params  []apiParam
+	// request пример тела запроса, по типу которого строится схема
+	request interface{}
+	// status код успешного ответа
+	status int
+	// responses пример тела успешного ответа, по типу которого строится
+	// схема; несколько -- ответ одного из этих видов; nil -- тела нет
+	responses []interface{}
+	// contentTypes если ответ не JSON
+	contentTypes []string
+	// rows пример строк ответа application/x-ndjson, по типу которых
+	// строится схема одной строки
+	rows interface{}
+	// errors коды ответов с jmodels.APIError
+	errors []int
+	// role роль, нужная для роута; пустая -- роут открыт всем
+	role string
+	// owner параметр пути с ID пользователя, от имени которого действует
+	// запрос: такой роут доступен только ему самому и admin
+	owner string
+}
+
+// apiRoutes роуты http api, общие для всех версий
+func (s *Server) apiRoutes() []*apiRoute {
+	withGame := []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusGatewayTimeout}
+	withParams := []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusGatewayTimeout}
+
+	return []*apiRoute{
+		{
+			method: "GET", path: "/leaderboard", handler: s.GetGlobalLeaderboard,
+			summary: "Общий рейтинг игроков по всем играм",
+			params: append(pageParamsDescription(defaultLeaderboardLimit),
+				queryEnumParam("formula", "формула рейтинга; по умолчанию из настроек", "", FormulaRanks, FormulaScores)),
+			status:    http.StatusOK,
+			responses: []interface{}{[]*jmodels.GlobalLeader{}},
+			errors:    withGame,
+		},
+		{
+			method: "GET", path: "/users/{user_id}/games", handler: s.GetUserGames,
+			summary:   "Игры пользователя и его места в них",
+			params:    []apiParam{pathIDParam("user_id", "ID пользователя")},
+			status:    http.StatusOK,
+			responses: []interface{}{[]*jmodels.UserGame{}},
+			errors:    withParams,
+		},
+		{
+			method: "GET", path: "/users/{user_id}/following", handler: s.GetFollowedUsers,
+			summary:   "ID пользователей, на которых подписан user_id",
+			params:    []apiParam{pathIDParam("user_id", "ID подписчика")},
+			status:    http.StatusOK,
+			responses: []interface{}{[]int64{}},
+			errors:    withParams,
+		},
+		{
+			method: "PUT", path: "/users/{user_id}/following/{followee_id}", handler: s.FollowUser,
+			summary: "Подписать user_id на followee_id",
+			params: []apiParam{pathIDParam("user_id", "ID подписчика"),
+				pathIDParam("followee_id", "на кого подписаться")},
+			status: http.StatusNoContent,
+			errors: withParams,
+			role:   rolePlayer,
+			owner:  "user_id",
+		},
+		{
+			method: "DELETE", path: "/users/{user_id}/following/{followee_id}", handler: s.UnfollowUser,
+			summary: "Отписать user_id от followee_id",
+			params: []apiParam{pathIDParam("user_id", "ID подписчика"),
+				pathIDParam("followee_id", "от кого отписаться")},
+			status: http.StatusNoContent,
+			errors: withGame,
+			role:   rolePlayer,
+			owner:  "user_id",
+		},
+		{
+			method: "GET", path: "/games", handler: s.GetGameList,
+			summary:   "Список игр",
+			status:    http.StatusOK,
+			responses: []interface{}{[]*jmodels.Game{}},
+			errors:    []int{http.StatusInternalServerError, http.StatusGatewayTimeout},
+		},
+		{
+			method: "GET", path: "/games/{game_slug}", handler: s.GetGame,
+			summary:   "Полная информация об игре",
+			params:    []apiParam{pathSlugParam()},
+			status:    http.StatusOK,
+			responses: []interface{}{&jmodels.GameFull{}},
+			errors:    []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusGatewayTimeout},
+		},
+		{
+			method: "GET", path: "/games/{game_slug}/leaderboard", handler: s.GetGameLeaderboard,
+			summary: "Leaderboard игры: по всем игрокам, только по users/followed_by " +
+				"(RankedUser) или за окно window (WindowLeader)",
+			params: append(append([]apiParam{pathSlugParam()}, pageParamsDescription(defaultLeaderboardLimit)...),
+				queryStringParam("users", "ID игроков через запятую", ""),
+				queryIDParam("followed_by", "подписки этого игрока вместе с ним"),
+				queryEnumParam("window", "leaderboard за текущий день/неделю/месяц", "", WindowDay, WindowWeek, WindowMonth),
+				queryStringParam("at", "дата внутри нужного окна, YYYY-MM-DD", "date"),
+			),
+			status: http.StatusOK,
+			responses: []interface{}{
+				[]*jmodels.ScoredUser{},
+				[]*jmodels.RankedUser{},
+				[]*jmodels.WindowLeader{},
+			},
+			errors: withGame,
+		},
+		{
+			method: "GET", path: "/games/{game_slug}/leaderboard/count", handler: s.GetGameTotalPlayers,
+			summary:   "Количество игроков в игре",
+			params:    []apiParam{pathSlugParam()},
+			status:    http.StatusOK,
+			responses: []interface{}{&jmodels.PlayersCount{}},
+			errors:    []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusGatewayTimeout},
+		},
+		{
+			method: "GET", path: "/games/{game_slug}/leaderboard/stats", handler: s.GetGameScoreStats,
+			summary: "Перцентили и гистограмма очков",
+			params: []apiParam{pathSlugParam(),
+				queryIntParam("buckets", "столбцов гистограммы", defaultStatsBuckets, 1, maxStatsBuckets)},
+			status:    http.StatusOK,
+			responses: []interface{}{&jmodels.ScoreStats{}},
+			errors:    withGame,
+		},
+		{
+			method: "GET", path: "/games/{game_slug}/leaderboard/export", handler: s.ExportGameLeaderboard,
+			summary: "Весь leaderboard игры потоком",
+			params: []apiParam{pathSlugParam(),
+				queryEnumParam("format", "формат выгрузки", ExportCSV, ExportCSV, ExportNDJSON)},
+			status:       http.StatusOK,
+			contentTypes: []string{exportContentTypes[ExportCSV], exportContentTypes[ExportNDJSON]},
+			rows:         []*jmodels.RankedUser{},
+			errors:       withGame,
+		},
+		{
+			method: "GET", path: "/admin/games/{game_slug}/users/{user_id}/score-events", handler: s.GetUserScoreEvents,
+			summary: "История изменений очков пользователя в игре",
+			params: append([]apiParam{pathSlugParam(), pathIDParam("user_id", "ID пользователя")},
+				pageParamsDescription(defaultEventsLimit)...),
+			status:    http.StatusOK,
+			responses: []interface{}{[]*jmodels.ScoreEvent{}},
+			errors:    withGame,
+			role:      roleAdmin,
+		},
+		{
+			method: "PATCH", path: "/games/{game_slug}", handler: s.UpdateGame,
+			summary:   "Изменить описание игры",
+			params:    []apiParam{pathSlugParam()},
+			request:   &jmodels.GameUpdate{},
+			status:    http.StatusOK,
+			responses: []interface{}{&jmodels.GameFull{}},
+			errors:    withGame,
+			role:      rolePlayer,
+		},
+		{
+			method: "PUT", path: "/games/{game_slug}/users/{user_id}/hidden", handler: s.HideUserScore,
+			summary: "Скрыть очки игрока из leaderboard и статистики",
+			params:  []apiParam{pathSlugParam(), pathIDParam("user_id", "ID игрока")},
+			status:  http.StatusNoContent,
+			errors:  withGame,
+			role:    rolePlayer,
+		},
+		{
+			method: "DELETE", path: "/games/{game_slug}/users/{user_id}/hidden", handler: s.ShowUserScore,
+			summary: "Вернуть скрытые очки игрока",
+			params:  []apiParam{pathSlugParam(), pathIDParam("user_id", "ID игрока")},
+			status:  http.StatusNoContent,
+			errors:  withGame,
+			role:    rolePlayer,
+		},
+		{
+			method: "GET", path: "/admin/users/{user_id}/grants", handler: s.GetUserGrants,
+			summary:   "Роли пользователя",
+			params:    []apiParam{pathIDParam("user_id", "ID пользователя")},
+			status:    http.StatusOK,
+			responses: []interface{}{[]*jmodels.Grant{}},
+			errors:    withParams,
+			role:      roleAdmin,
+		},
+		{
+			method: "PUT", path: "/admin/users/{user_id}/grants/{role}", handler: s.AddGrant,
+			summary: "Выдать роль на игру game или на все игры",
+			params: []apiParam{pathIDParam("user_id", "ID пользователя"), pathRoleParam(),
+				queryStringParam("game", "slug игры; без него -- роль на все игры", "")},
+			status: http.StatusNoContent,
+			errors: withGame,
+			role:   roleAdmin,
+		},
+		{
+			method: "DELETE", path: "/admin/users/{user_id}/grants/{role}", handler: s.DeleteGrant,
+			summary: "Забрать роль на игру game или на все игры",
+			params: []apiParam{pathIDParam("user_id", "ID пользователя"), pathRoleParam(),
+				queryStringParam("game", "slug игры; без него -- роль на все игры", "")},
+			status: http.StatusNoContent,
+			errors: withGame,
+			role:   roleAdmin,
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+// ScoreEventModel одно изменение очков пользователя в игре
+type ScoreEventModel struct {
+	ID        int64
+	UserID    int64
+	OldScore  int32
+	NewScore  int32
+	Reason    string
+	Source    string
+	MatchID   sql.NullString
+	CreatedAt time.Time
+}
+
+// GetMatchID возвращает MatchID или пустую строку, если его нет в базе
+func (e *ScoreEventModel) GetMatchID() string {
+	if e.MatchID.Valid {
+		return e.MatchID.String
+	}
+
+	return ""
+}
+
+// ScoreChange изменение очков вместе с его обоснованием
+type ScoreChange struct {
+	Delta   int32
+	Reason  string
+	Source  string
+	MatchID string
+}
+
+// GetUserScoreEvents история очков пользователя в игре, от новых к старым
+func (gs *AccessObject) GetUserScoreEvents(ctx context.Context, slug string, userID int64,
+	limit, offset int) ([]*ScoreEventModel, error) {
+	ctx = withQueryMethod(ctx, "GetUserScoreEvents")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, internalError(ctx, "can not open GetUserScoreEvents transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrNotExists
+		}
+
+		return nil, internalError(ctx, "GetUserScoreEvents can not get game by slug: %v", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, user_id, old_score, new_score, reason, source, match_id, created_at
+					FROM score_events WHERE game_id = $1 AND user_id = $2
+					ORDER BY created_at DESC, id DESC OFFSET $3 LIMIT $4;`, g.ID, userID, offset, limit)
+	if err != nil {
+		return nil, internalError(ctx, "get score events error: %v", err)
+	}
+	defer rows.Close()
+
+	events := make([]*ScoreEventModel, 0)
+	for rows.Next() {
+		e := &ScoreEventModel{}
+		err = rows.Scan(&e.ID, &e.UserID, &e.OldScore, &e.NewScore,
+			&e.Reason, &e.Source, &e.MatchID, &e.CreatedAt)
+		if err != nil {
+			return nil, internalError(ctx, "get score events scan error: %v", err)
+		}
+		events = append(events, e)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, internalError(ctx, "can not commit GetUserScoreEvents transaction: %v", err)
+	}
+
+	return events, nil
+}
+
+// UpdateUserScore добавляет change.Delta к очкам пользователя в игре.
+// Событие в score_events пишет триггер на users_games в этой же транзакции,
+// а причину, сервис и матч он берёт из локальных настроек транзакции
+func (gs *AccessObject) UpdateUserScore(ctx context.Context, slug string, userID int64, change *ScoreChange) (int32, int32, error) {
+	ctx = withQueryMethod(ctx, "UpdateUserScore")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, internalError(ctx, "can not open UpdateUserScore transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, utils.ErrNotExists
+		}
+
+		return 0, 0, internalError(ctx, "UpdateUserScore can not get game by slug: %v", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `SELECT set_config('warscript.score_reason', $1, true),
+					set_config('warscript.score_source', $2, true),
+					set_config('warscript.score_match_id', $3, true);`,
+		change.Reason, change.Source, change.MatchID)
+	if err != nil {
+		return 0, 0, internalError(ctx, "can not set score change metadata: %v", err)
+	}
+
+	// старые очки считаем из новых: SELECT ... FOR UPDATE в этой же команде
+	// выполнился бы после ON CONFLICT и пропустил уже обновлённую строку
+	var oldScore, newScore int32
+	row := tx.QueryRowContext(ctx, `INSERT INTO users_games (user_id, game_id, score) VALUES ($1, $2, $3)
+					ON CONFLICT (user_id, game_id) DO UPDATE SET score = users_games.score + EXCLUDED.score
+					RETURNING score - $3, score;`, userID, g.ID, change.Delta)
+	if err = row.Scan(&oldScore, &newScore); err != nil {
+		return 0, 0, internalError(ctx, "can not update user score: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return 0, 0, internalError(ctx, "can not commit UpdateUserScore transaction: %v", err)
+	}
+
+	return oldScore, newScore, nil
+}
@@ -12,6 +12,15 @@ echo -e "# Starting docker.\n"
 chmod 600 ./2019_1_HotCode_id_rsa.pem
 ssh-keyscan -H 89.208.198.192 >> ~/.ssh/known_hosts
 ssh -i ./2019_1_HotCode_id_rsa.pem ubuntu@89.208.198.192 docker pull $DOCKER_USER/warscript-games
+
+# serve не стартует на старой схеме, поэтому мигрируем до перезапуска контейнеров
+echo -e "# Migrating schema.\n"
+ssh -i ./2019_1_HotCode_id_rsa.pem ubuntu@89.208.198.192 docker run --rm -e CONSUL_ADDR=$CONSUL_ADDR \
+                                                                -e VAULT_ADDR=$VAULT_ADDR \
+                                                                -e VAULT_TOKEN=$VAULT_TOKEN \
+                                                                --net=host $DOCKER_USER/warscript-games \
+                                                                /warscript-games migrate
+
 for (( c=1; c<=$CONTAINERS_COUNT; c++ ))
 do
     ssh -i ./2019_1_HotCode_id_rsa.pem ubuntu@89.208.198.192 docker stop warscript-games.$c
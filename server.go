@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Server сервис warscript-games: всё, что нужно хэндлерам http и gRPC.
+// Глобального состояния нет, так что сервис можно поднять прямо в тесте
+// или рядом с другим в том же процессе
+type Server struct {
+	games  GameAccessObject
+	auth   models.AuthClient
+	logger *logrus.Logger
+	cfg    *Config
+
+	// statsCache кэш распределений очков по играм
+	statsCache *ttlCache
+	// globalFormula формула общего рейтинга, если клиент не указал свою
+	globalFormula string
+	// location часовой пояс, в котором считаются границы окон
+	location *time.Location
+	// tracer nil, если трейсинг выключен
+	tracer *tracer
+}
+
+// NewServer сервис поверх DAO games и клиента warscript-users auth
+func NewServer(cfg *Config, games GameAccessObject, auth models.AuthClient, logger *logrus.Logger) *Server {
+	s := &Server{
+		games:         games,
+		auth:          auth,
+		logger:        logger,
+		cfg:           cfg,
+		statsCache:    newTTLCache(statsCacheTTL),
+		globalFormula: cfg.GlobalFormula,
+		location:      cfg.location,
+		tracer:        newTracer(cfg.TracingEndpoint, "warscript-games", logger),
+	}
+	if s.globalFormula == "" {
+		s.globalFormula = FormulaRanks
+	}
+	if s.location == nil {
+		s.location = time.UTC
+	}
+
+	return s
+}
+
+// GamesManager gRPC сервер этого сервиса
+func (s *Server) GamesManager() *GamesManager {
+	return &GamesManager{srv: s}
+}
+
+// queryContext контекст обычного запроса к api: отменяется, когда клиент
+// ушёл, и ограничен QueryTimeout
+func (s *Server) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.cfg.QueryTimeout)
+}
+
+// exportContext контекст выгрузки leaderboard, ограничен ExportTimeout
+func (s *Server) exportContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, s.cfg.ExportTimeout)
+}
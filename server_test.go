@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServersIndependent(t *testing.T) {
+	withPong := initTests()
+	empty := newTestServer(&gameTest{})
+
+	cases := []struct {
+		srv          *Server
+		expectedCode int
+	}{
+		{srv: withPong, expectedCode: http.StatusOK},
+		{srv: empty, expectedCode: http.StatusNotFound},
+	}
+
+	for i, c := range cases {
+		ts := httptest.NewServer(c.srv.httpHandler(newHealthChecker(&pingerTest{}, nil)))
+
+		resp, err := http.Get(ts.URL + "/v1/games/pong")
+		if err != nil {
+			t.Fatalf("[%d] TestServersIndependent got unexpected error: %v", i, err)
+		}
+		resp.Body.Close()
+		ts.Close()
+
+		if resp.StatusCode != c.expectedCode {
+			t.Errorf("[%d] TestServersIndependent got code %d, expected %d", i, resp.StatusCode, c.expectedCode)
+		}
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	ts := httptest.NewServer(initTests().httpHandler(newHealthChecker(&pingerTest{}, nil)))
+	defer ts.Close()
+
+	cases := []struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		{method: "GET", path: "/v1/nope", expectedCode: http.StatusNotFound, expectedBody: `{"message":"not_found"}`},
+		{method: "POST", path: "/v1/games", expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: `{"message":"method_not_allowed"}`},
+	}
+
+	for i, c := range cases {
+		req, _ := http.NewRequest(c.method, ts.URL+c.path, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("[%d] TestRouterErrors got unexpected error: %v", i, err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != c.expectedCode || string(body) != c.expectedBody {
+			t.Errorf("[%d] TestRouterErrors got %d %s, expected %d %s",
+				i, resp.StatusCode, body, c.expectedCode, c.expectedBody)
+		}
+	}
+}
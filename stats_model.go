@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+)
+
+// ScoreBucketModel столбец гистограммы очков: [From, To)
+type ScoreBucketModel struct {
+	From  float64
+	To    float64
+	Count int64
+}
+
+// ScoreStatsModel распределение очков в users_games для одной игры
+type ScoreStatsModel struct {
+	Count   int64
+	Min     int32
+	Max     int32
+	P50     float64
+	P90     float64
+	P99     float64
+	Buckets []*ScoreBucketModel
+}
+
+// GetGameScoreStatsBySlug считает перцентили и гистограмму очков игры.
+// Всё считается на стороне базы через percentile_cont и width_bucket,
+// поэтому в память не поднимается ни одной строки users_games
+func (gs *AccessObject) GetGameScoreStatsBySlug(ctx context.Context, slug string, buckets int) (*ScoreStatsModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameScoreStatsBySlug")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, internalError(ctx, "can not open GetGameScoreStatsBySlug transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrNotExists
+		}
+
+		return nil, internalError(ctx, "GetGameScoreStatsBySlug can not get game by slug: %v", err)
+	}
+
+	stats := &ScoreStatsModel{}
+	row := tx.QueryRowContext(ctx, `SELECT count(*), coalesce(min(score), 0), coalesce(max(score), 0),
+					coalesce(percentile_cont(0.5) WITHIN GROUP (ORDER BY score), 0),
+					coalesce(percentile_cont(0.9) WITHIN GROUP (ORDER BY score), 0),
+					coalesce(percentile_cont(0.99) WITHIN GROUP (ORDER BY score), 0)
+					FROM visible_users_games WHERE game_id = $1;`, g.ID)
+	if err = row.Scan(&stats.Count, &stats.Min, &stats.Max,
+		&stats.P50, &stats.P90, &stats.P99); err != nil {
+		return nil, internalError(ctx, "get game score percentiles error: %v", err)
+	}
+
+	stats.Buckets = make([]*ScoreBucketModel, 0, buckets)
+	if stats.Count == 0 {
+		return stats, nil
+	}
+
+	// верхняя граница width_bucket не включается, поэтому
+	// сдвигаем её на единицу, чтобы максимум попал в последний столбец
+	low, high := float64(stats.Min), float64(stats.Max)+1
+	width := (high - low) / float64(buckets)
+	for i := 0; i < buckets; i++ {
+		stats.Buckets = append(stats.Buckets, &ScoreBucketModel{
+			From: low + float64(i)*width,
+			To:   low + float64(i+1)*width,
+		})
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT width_bucket(score, $2, $3, $4) AS bucket, count(*)
+					FROM visible_users_games WHERE game_id = $1
+					GROUP BY bucket ORDER BY bucket;`, g.ID, low, high, buckets)
+	if err != nil {
+		return nil, internalError(ctx, "get game score histogram error: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket int
+		var count int64
+		if err = rows.Scan(&bucket, &count); err != nil {
+			return nil, internalError(ctx, "get game score histogram scan error: %v", err)
+		}
+
+		// width_bucket нумерует столбцы с единицы
+		if bucket >= 1 && bucket <= buckets {
+			stats.Buckets[bucket-1].Count = count
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, internalError(ctx, "get game score histogram rows error: %v", err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, internalError(ctx, "can not commit GetGameScoreStatsBySlug transaction: %v", err)
+	}
+
+	return stats, nil
+}
@@ -1,19 +1,63 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"reflect"
+	"time"
 
+	"github.com/HotCodeGroup/warscript-utils/models"
 	"github.com/HotCodeGroup/warscript-utils/testutils"
 	"github.com/HotCodeGroup/warscript-utils/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// fakeAuthClient дополняет testutils.FakeAuthClient методами,
+// которых в нём ещё нет, чтобы он реализовывал models.AuthClient
+type fakeAuthClient struct {
+	testutils.FakeAuthClient
+}
+
+// GetUserBySecret мок функции получения пользователя по секрету VK
+func (c *fakeAuthClient) GetUserBySecret(ctx context.Context,
+	in *models.VkSecret, opts ...grpc.CallOption) (*models.InfoUser, error) {
+	return nil, nil
+}
+
+// sessionAuthClient fakeAuthClient с сессиями: токен -> ID пользователя
+type sessionAuthClient struct {
+	fakeAuthClient
+	sessions map[string]int64
+}
+
+// GetSessionInfo мок проверки сессии; чужой токен -- Unauthenticated
+func (c *sessionAuthClient) GetSessionInfo(ctx context.Context,
+	in *models.SessionToken, opts ...grpc.CallOption) (*models.SessionPayload, error) {
+	if err := c.NextFail(); err != nil {
+		return nil, err
+	}
+
+	id, ok := c.sessions[in.Token]
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "session not found")
+	}
+
+	return &models.SessionPayload{ID: id}, nil
+}
+
 type gameTest struct {
-	games map[string]*GameModel
+	games   map[string]*GameModel
+	follows map[int64][]int64
+	grants  []*GrantModel
+	// hidden скрытые очки: slug -> ID игроков
+	hidden map[string]map[int64]bool
 
 	testutils.Failer
 }
 
-func (gt *gameTest) GetGameBySlug(slug string) (*GameModel, error) {
+func (gt *gameTest) GetGameBySlug(ctx context.Context, slug string) (*GameModel, error) {
 	if err := gt.NextFail(); err != nil {
 		return nil, err
 	}
@@ -26,7 +70,7 @@ func (gt *gameTest) GetGameBySlug(slug string) (*GameModel, error) {
 	return g, nil
 }
 
-func (gt *gameTest) GetGameTotalPlayersBySlug(slug string) (int64, error) {
+func (gt *gameTest) GetGameTotalPlayersBySlug(ctx context.Context, slug string) (int64, error) {
 	if err := gt.NextFail(); err != nil {
 		return 0, err
 	}
@@ -34,7 +78,7 @@ func (gt *gameTest) GetGameTotalPlayersBySlug(slug string) (int64, error) {
 	return 1, nil
 }
 
-func (gt *gameTest) GetGameList() ([]*GameModel, error) {
+func (gt *gameTest) GetGameList(ctx context.Context) ([]*GameModel, error) {
 	if err := gt.NextFail(); err != nil {
 		return nil, err
 	}
@@ -47,7 +91,7 @@ func (gt *gameTest) GetGameList() ([]*GameModel, error) {
 	return games, nil
 }
 
-func (gt *gameTest) GetGameLeaderboardBySlug(slug string, limit, offset int) ([]*ScoredUserModel, error) {
+func (gt *gameTest) GetGameLeaderboardBySlug(ctx context.Context, slug string, limit, offset int) ([]*ScoredUserModel, error) {
 	if err := gt.NextFail(); err != nil {
 		return nil, err
 	}
@@ -68,3 +112,346 @@ func (gt *gameTest) GetGameLeaderboardBySlug(slug string, limit, offset int) ([]
 
 	return leaderboard, nil
 }
+
+func (gt *gameTest) GetGameScoreStatsBySlug(ctx context.Context, slug string, buckets int) (*ScoreStatsModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := gt.games[slug]; !ok {
+		return nil, utils.ErrNotExists
+	}
+
+	return &ScoreStatsModel{
+		Count: 3,
+		Min:   0,
+		Max:   9,
+		P50:   5,
+		P90:   9,
+		P99:   9,
+		Buckets: []*ScoreBucketModel{
+			{From: 0, To: 5, Count: 1},
+			{From: 5, To: 10, Count: 2},
+		},
+	}, nil
+}
+
+func (gt *gameTest) GetGlobalLeaderboard(ctx context.Context, formula string, limit, offset int) ([]*GlobalScoredUserModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	leaderboard := []*GlobalScoredUserModel{
+		{
+			ID:          1,
+			Username:    "GDVFox",
+			PhotoUUID:   sql.NullString{String: "2eb4a823-3a6d-4cba-8767-4d4946890f4f", Valid: true},
+			Rating:      2,
+			Rank:        1,
+			GamesPlayed: 2,
+		},
+		{
+			ID:          2,
+			Username:    "GDVFox1337",
+			Rating:      0.5,
+			Rank:        2,
+			GamesPlayed: 1,
+		},
+	}
+
+	return leaderboard, nil
+}
+
+func (gt *gameTest) GetUserGames(ctx context.Context, userID int64) ([]*UserGameModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	if userID != 1 {
+		return []*UserGameModel{}, nil
+	}
+
+	return []*UserGameModel{
+		{
+			Slug:           "pong",
+			Title:          "Pong",
+			BackgroundUUID: sql.NullString{String: "2eb4a823-3a6d-5xyz-8767-4d4946890f4f", Valid: true},
+			Score:          1337,
+			Rank:           2,
+			Percentile:     75,
+			LastPlayed:     time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (gt *gameTest) GetGameLeaderboardForUsers(ctx context.Context, slug string, userIDs []int64,
+	limit, offset int) ([]*RankedUserModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	leaderboard := make([]*RankedUserModel, 0, len(userIDs))
+	for i, id := range userIDs {
+		leaderboard = append(leaderboard, &RankedUserModel{
+			ScoredUserModel: ScoredUserModel{
+				ID:       id,
+				Username: "GDVFox",
+				Score:    int32(100 - i),
+			},
+			Rank:       int64(i + 1),
+			GlobalRank: int64(10 * (i + 1)),
+		})
+	}
+
+	return leaderboard, nil
+}
+
+func (gt *gameTest) GetFollowedUsers(ctx context.Context, followerID int64) ([]int64, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	// как и в базе: нет подписок -- пустой список, а не nil
+	return append(make([]int64, 0), gt.follows[followerID]...), nil
+}
+
+func (gt *gameTest) FollowUser(ctx context.Context, followerID, followeeID int64) error {
+	if err := gt.NextFail(); err != nil {
+		return err
+	}
+
+	if followerID == followeeID {
+		return &utils.ValidationError{
+			"followee_id": utils.ErrInvalid.Error(),
+		}
+	}
+
+	if gt.follows == nil {
+		gt.follows = make(map[int64][]int64)
+	}
+	gt.follows[followerID] = append(gt.follows[followerID], followeeID)
+
+	return nil
+}
+
+func (gt *gameTest) UnfollowUser(ctx context.Context, followerID, followeeID int64) error {
+	if err := gt.NextFail(); err != nil {
+		return err
+	}
+
+	for i, id := range gt.follows[followerID] {
+		if id == followeeID {
+			gt.follows[followerID] = append(gt.follows[followerID][:i], gt.follows[followerID][i+1:]...)
+			return nil
+		}
+	}
+
+	return utils.ErrNotExists
+}
+
+func (gt *gameTest) GetGameWindowLeaderboardBySlug(ctx context.Context, slug string, w *LeaderboardWindow,
+	limit, offset int) ([]*WindowScoredUserModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	return []*WindowScoredUserModel{
+		{
+			ID:       1,
+			Username: "GDVFox",
+			Points:   int64(w.End.Sub(w.Start) / time.Hour),
+			Rank:     1,
+		},
+	}, nil
+}
+
+func (gt *gameTest) SnapshotWindow(ctx context.Context, w *LeaderboardWindow) error {
+	return gt.NextFail()
+}
+
+func (gt *gameTest) RecomputeWindow(ctx context.Context, w *LeaderboardWindow) error {
+	return gt.NextFail()
+}
+
+func (gt *gameTest) UpsertGame(ctx context.Context, g *GameModel) (bool, error) {
+	if err := gt.NextFail(); err != nil {
+		return false, err
+	}
+
+	if old, ok := gt.games[g.Slug]; ok {
+		g.ID = old.ID
+		if reflect.DeepEqual(old, g) {
+			return false, nil
+		}
+	} else {
+		g.ID = int64(len(gt.games) + 1)
+	}
+
+	saved := *g
+	gt.games[g.Slug] = &saved
+	return true, nil
+}
+
+func (gt *gameTest) GetUserScoreEvents(ctx context.Context, slug string, userID int64,
+	limit, offset int) ([]*ScoreEventModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := gt.games[slug]; !ok {
+		return nil, utils.ErrNotExists
+	}
+
+	return []*ScoreEventModel{
+		{
+			ID:        2,
+			UserID:    userID,
+			OldScore:  10,
+			NewScore:  25,
+			Reason:    "match won",
+			Source:    "warscript-bots",
+			MatchID:   sql.NullString{String: "42", Valid: true},
+			CreatedAt: time.Date(2019, 5, 20, 12, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (gt *gameTest) UpdateUserScore(ctx context.Context, slug string, userID int64, change *ScoreChange) (int32, int32, error) {
+	if err := gt.NextFail(); err != nil {
+		return 0, 0, err
+	}
+
+	if _, ok := gt.games[slug]; !ok {
+		return 0, 0, utils.ErrNotExists
+	}
+
+	return 10, 10 + change.Delta, nil
+}
+
+func (gt *gameTest) ExportGameLeaderboard(ctx context.Context, slug string, batchSize int,
+	fn func([]*RankedUserModel) error) error {
+	if err := gt.NextFail(); err != nil {
+		return err
+	}
+
+	if _, ok := gt.games[slug]; !ok {
+		return utils.ErrNotExists
+	}
+
+	leaderboard := []*RankedUserModel{
+		{
+			ScoredUserModel: ScoredUserModel{
+				ID:        1,
+				Username:  "GDVFox",
+				PhotoUUID: sql.NullString{String: "2eb4a823-3a6d-4cba-8767-4d4946890f4f", Valid: true},
+				Active:    true,
+				Score:     1337,
+			},
+			Rank:       1,
+			GlobalRank: 1,
+		},
+		{
+			ScoredUserModel: ScoredUserModel{
+				ID:       2,
+				Username: "GDV,Fox",
+				Score:    1337,
+			},
+			Rank:       1,
+			GlobalRank: 1,
+		},
+		{
+			ScoredUserModel: ScoredUserModel{
+				ID:       3,
+				Username: "newbie",
+				Score:    0,
+			},
+			Rank:       3,
+			GlobalRank: 3,
+		},
+	}
+
+	for len(leaderboard) > 0 {
+		n := batchSize
+		if n > len(leaderboard) {
+			n = len(leaderboard)
+		}
+
+		if err := fn(leaderboard[:n]); err != nil {
+			return err
+		}
+		leaderboard = leaderboard[n:]
+	}
+
+	return nil
+}
+
+func (gt *gameTest) GetUserGrants(ctx context.Context, userID int64) ([]*GrantModel, error) {
+	if err := gt.NextFail(); err != nil {
+		return nil, err
+	}
+
+	grants := make([]*GrantModel, 0)
+	for _, g := range gt.grants {
+		if g.UserID == userID {
+			grants = append(grants, g)
+		}
+	}
+
+	return grants, nil
+}
+
+func (gt *gameTest) AddGrant(ctx context.Context, g *GrantModel) (bool, error) {
+	if err := gt.NextFail(); err != nil {
+		return false, err
+	}
+
+	if _, ok := gt.games[g.GameSlug]; g.GameSlug != "" && !ok {
+		return false, utils.ErrNotExists
+	}
+	for _, old := range gt.grants {
+		if old.UserID == g.UserID && old.GameSlug == g.GameSlug && old.Role == g.Role {
+			return false, nil
+		}
+	}
+
+	g.ID = int64(len(gt.grants) + 1)
+	g.CreatedAt = time.Date(2019, 5, 1, 12, 0, 0, 0, time.UTC)
+	saved := *g
+	gt.grants = append(gt.grants, &saved)
+	return true, nil
+}
+
+func (gt *gameTest) DeleteGrant(ctx context.Context, userID int64, slug, role string) error {
+	if err := gt.NextFail(); err != nil {
+		return err
+	}
+
+	for i, g := range gt.grants {
+		if g.UserID == userID && g.GameSlug == slug && g.Role == role {
+			gt.grants = append(gt.grants[:i], gt.grants[i+1:]...)
+			return nil
+		}
+	}
+
+	return utils.ErrNotExists
+}
+
+// SetScoreHidden игроки в фейке -- те же 1 и 2, что и в leaderboard
+func (gt *gameTest) SetScoreHidden(ctx context.Context, slug string, userID int64, hidden bool) error {
+	if err := gt.NextFail(); err != nil {
+		return err
+	}
+
+	if _, ok := gt.games[slug]; !ok || (userID != 1 && userID != 2) {
+		return utils.ErrNotExists
+	}
+	if gt.hidden == nil {
+		gt.hidden = make(map[string]map[int64]bool)
+	}
+	if gt.hidden[slug] == nil {
+		gt.hidden[slug] = make(map[int64]bool)
+	}
+	gt.hidden[slug][userID] = hidden
+
+	return nil
+}
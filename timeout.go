@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultQueryTimeout таймаут обычного запроса к api
+	defaultQueryTimeout = 3 * time.Second
+	// defaultExportTimeout таймаут выгрузки leaderboard, она идёт потоком
+	defaultExportTimeout = 10 * time.Minute
+	// defaultUsersTimeout таймаут похода в warscript-users
+	defaultUsersTimeout = time.Second
+)
+
+// errTimeout операция не уложилась в свой таймаут: 504 в http,
+// DeadlineExceeded в gRPC
+var errTimeout = errors.New("deadline_exceeded")
+
+// withTimeout ctx с таймаутом timeout; 0 -- без своего таймаута,
+// остаётся только дедлайн родителя
+func withTimeout(ctx context.Context, timeout Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Duration(timeout))
+}
+
+// internalError ErrInternal с описанием, а если у ctx уже вышло время --
+// errTimeout: драйвер в этом случае отдаёт свою ошибку, а не ctx.Err()
+func internalError(ctx context.Context, format string, args ...interface{}) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Wrapf(errTimeout, format, args...)
+	}
+
+	return errors.Wrapf(utils.ErrInternal, format, args...)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/pkg/errors"
+)
+
+func TestInternalError(t *testing.T) {
+	if err := internalError(context.Background(), "get game error: %v", "boom"); errors.Cause(err) != utils.ErrInternal {
+		t.Errorf("TestInternalError got unexpected error: %v, expected: %v", err, utils.ErrInternal)
+	}
+
+	ctx, cancel := withTimeout(context.Background(), Duration(time.Nanosecond))
+	defer cancel()
+	<-ctx.Done()
+	if err := internalError(ctx, "get game error: %v", "boom"); errors.Cause(err) != errTimeout {
+		t.Errorf("TestInternalError got unexpected error: %v, expected: %v", err, errTimeout)
+	}
+
+	// без таймаута остаётся только отмена родителя
+	ctx, cancel = withTimeout(context.Background(), 0)
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("TestInternalError expected context without deadline")
+	}
+	cancel()
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// traceparentHeader заголовок (и метаданные gRPC) W3C Trace Context
+	traceparentHeader = "traceparent"
+
+	// tracingBatchSize сколько спанов отправляем в коллектор за раз
+	tracingBatchSize = 512
+	// tracingQueueSize сколько законченных спанов ждут отправки;
+	// если коллектор не успевает, новые спаны выбрасываются
+	tracingQueueSize = 4096
+	// tracingFlushInterval как часто отправляем неполную пачку
+	tracingFlushInterval = 5 * time.Second
+	// tracingExportTimeout сколько ждём коллектор на одну пачку
+	tracingExportTimeout = 10 * time.Second
+)
+
+// spanKind вид спана, значения как в OTLP
+type spanKind int
+
+const (
+	spanServer spanKind = 2
+	spanClient spanKind = 3
+)
+
+// spanContext то, что передаётся между сервисами в traceparent
+type spanContext struct {
+	traceID [16]byte
+	spanID  [8]byte
+	sampled bool
+}
+
+// parseTraceparent разбирает traceparent вида
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func parseTraceparent(v string) (spanContext, bool) {
+	sc := spanContext{}
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return sc, false
+	}
+	// в версии 00 полей ровно четыре, в следующих могут добавиться
+	if parts[0] == "00" && len(parts) != 4 {
+		return sc, false
+	}
+
+	if _, err := hex.Decode(sc.traceID[:], []byte(parts[1])); err != nil || sc.traceID == [16]byte{} {
+		return sc, false
+	}
+	if _, err := hex.Decode(sc.spanID[:], []byte(parts[2])); err != nil || sc.spanID == [8]byte{} {
+		return sc, false
+	}
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil {
+		return sc, false
+	}
+	sc.sampled = flags[0]&1 == 1
+
+	return sc, true
+}
+
+// traceparent заголовок для передачи дальше
+func (sc spanContext) traceparent() string {
+	flags := "00"
+	if sc.sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.traceID[:]), hex.EncodeToString(sc.spanID[:]), flags)
+}
+
+type spanAttr struct {
+	key   string
+	value interface{}
+}
+
+// span одна операция трейса. Все методы можно звать на nil: так выглядит
+// спан, когда трейсинг выключен
+type span struct {
+	tracer   *tracer
+	name     string
+	kind     spanKind
+	sc       spanContext
+	parentID [8]byte
+	start    time.Time
+	end      time.Time
+	attrs    []spanAttr
+	errMsg   string
+}
+
+// SetAttr атрибут спана, value -- string или int
+func (s *span) SetAttr(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, spanAttr{key: key, value: value})
+}
+
+// SetError помечает спан ошибкой
+func (s *span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.errMsg = err.Error()
+}
+
+// End заканчивает спан и отдаёт его на отправку
+func (s *span) End() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	if s.sc.sampled {
+		s.tracer.enqueue(s)
+	}
+}
+
+type spanKey struct{}
+
+// spanFromContext текущий спан запроса или nil
+func spanFromContext(ctx context.Context) *span {
+	s, _ := ctx.Value(spanKey{}).(*span)
+	return s
+}
+
+// spanExporter отправляет законченные спаны в коллектор
+type spanExporter interface {
+	Export(ctx context.Context, spans []*span) error
+}
+
+// tracer создаёт корневые спаны входящих запросов и копит законченные
+// для exporter. nil *tracer -- трейсинг выключен
+type tracer struct {
+	exporter spanExporter
+	queue    chan *span
+	logger   *logrus.Logger
+}
+
+// newTracer трейсер с отправкой в OTLP/HTTP коллектор endpoint;
+// пустой endpoint -- трейсинг выключен
+func newTracer(endpoint, service string, logger *logrus.Logger) *tracer {
+	if endpoint == "" {
+		return nil
+	}
+
+	return newTracerWithExporter(newOTLPExporter(endpoint, service), logger)
+}
+
+func newTracerWithExporter(exporter spanExporter, logger *logrus.Logger) *tracer {
+	return &tracer{
+		exporter: exporter,
+		queue:    make(chan *span, tracingQueueSize),
+		logger:   logger,
+	}
+}
+
+func newSpanID() [8]byte {
+	id := [8]byte{}
+	//nolint: errcheck
+	rand.Read(id[:])
+	return id
+}
+
+func newTraceID() [16]byte {
+	id := [16]byte{}
+	//nolint: errcheck
+	rand.Read(id[:])
+	return id
+}
+
+// startRootSpan спан входящего запроса: продолжает трейс из traceparent,
+// если он пришёл, иначе начинает новый
+func (t *tracer) startRootSpan(ctx context.Context, name string, kind spanKind,
+	traceparent string) (context.Context, *span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	s := &span{
+		tracer: t,
+		name:   name,
+		kind:   kind,
+		start:  time.Now(),
+	}
+	if parent, ok := parseTraceparent(traceparent); ok {
+		s.sc = parent
+		s.parentID = parent.spanID
+	} else {
+		s.sc = spanContext{traceID: newTraceID(), sampled: true}
+	}
+	s.sc.spanID = newSpanID()
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// startSpan дочерний спан текущего; если трейса в ctx нет, спана тоже нет
+func startSpan(ctx context.Context, name string, kind spanKind) (context.Context, *span) {
+	parent := spanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	s := &span{
+		tracer:   parent.tracer,
+		name:     name,
+		kind:     kind,
+		sc:       parent.sc,
+		parentID: parent.sc.spanID,
+		start:    time.Now(),
+	}
+	s.sc.spanID = newSpanID()
+
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+// outgoingTraceparent передаёт текущий спан дальше в метаданных gRPC
+func outgoingTraceparent(ctx context.Context) context.Context {
+	if s := spanFromContext(ctx); s != nil {
+		return metadata.AppendToOutgoingContext(ctx, traceparentHeader, s.sc.traceparent())
+	}
+
+	return ctx
+}
+
+func (t *tracer) enqueue(s *span) {
+	select {
+	case t.queue <- s:
+	default:
+		// коллектор не успевает -- лучше потерять спан, чем тормозить запросы
+	}
+}
+
+// run отправляет спаны пачками, пока не закроют stop; остаток
+// очереди отправляется перед выходом
+func (t *tracer) run(stop <-chan struct{}) {
+	if t == nil {
+		return
+	}
+
+	ticker := time.NewTicker(tracingFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*span, 0, tracingBatchSize)
+	for {
+		select {
+		case s := <-t.queue:
+			batch = append(batch, s)
+			if len(batch) >= tracingBatchSize {
+				batch = t.export(batch)
+			}
+		case <-ticker.C:
+			batch = t.export(batch)
+		case <-stop:
+			for {
+				select {
+				case s := <-t.queue:
+					batch = append(batch, s)
+				default:
+					t.export(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// export отправляет пачку и возвращает пустой буфер для следующей
+func (t *tracer) export(batch []*span) []*span {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tracingExportTimeout)
+	defer cancel()
+	if err := t.exporter.Export(ctx, batch); err != nil {
+		t.logger.Errorf("can not export %d spans: %s", len(batch), err)
+	}
+
+	return make([]*span, 0, tracingBatchSize)
+}
+
+// httpTracingMiddleware спан входящего http запроса по шаблону роута
+func (s *Server) httpTracingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+
+		ctx, sp := s.tracer.startRootSpan(r.Context(), r.Method+" "+route, spanServer,
+			r.Header.Get(traceparentHeader))
+		if sp == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer sp.End()
+
+		rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		sp.SetAttr("http.method", r.Method)
+		sp.SetAttr("http.route", route)
+		sp.SetAttr("http.status_code", rec.code)
+		sp.SetAttr("request.id", requestID(ctx))
+		if rec.code >= http.StatusInternalServerError {
+			sp.SetError(fmt.Errorf("%d %s", rec.code, http.StatusText(rec.code)))
+		}
+	})
+}
+
+// grpcTraceparent traceparent из входящих метаданных
+func grpcTraceparent(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(traceparentHeader); len(values) != 0 {
+			return values[0]
+		}
+	}
+
+	return ""
+}
+
+// endGRPCSpan атрибуты gRPC вызова и итоговый статус
+func endGRPCSpan(ctx context.Context, sp *span, method string, err error) {
+	sp.SetAttr("rpc.system", "grpc")
+	sp.SetAttr("rpc.method", method)
+	sp.SetAttr("rpc.grpc.status_code", int(status.Code(err)))
+	sp.SetAttr("request.id", requestID(ctx))
+	sp.SetError(err)
+	sp.End()
+}
+
+// unaryTracing спан входящего gRPC вызова
+func unaryTracing(t *tracer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, sp := t.startRootSpan(ctx, strings.TrimPrefix(info.FullMethod, "/"), spanServer,
+			grpcTraceparent(ctx))
+		resp, err := handler(ctx, req)
+		endGRPCSpan(ctx, sp, info.FullMethod, err)
+
+		return resp, err
+	}
+}
+
+func streamTracing(t *tracer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+		ctx, sp := t.startRootSpan(ss.Context(), strings.TrimPrefix(info.FullMethod, "/"), spanServer,
+			grpcTraceparent(ss.Context()))
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		endGRPCSpan(ctx, sp, info.FullMethod, err)
+
+		return err
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	cases := []struct {
+		value           string
+		expectedOK      bool
+		expectedSampled bool
+	}{
+		{value: testTraceparent, expectedOK: true, expectedSampled: true},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", expectedOK: true},
+		{value: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", expectedOK: true, expectedSampled: true},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{value: "00-4bf92f3577b34da6a3ce929d0e0e47zz-00f067aa0ba902b7-01"},
+		{value: ""},
+	}
+
+	for i, c := range cases {
+		sc, ok := parseTraceparent(c.value)
+		if ok != c.expectedOK || sc.sampled != c.expectedSampled {
+			t.Errorf("[%d] TestParseTraceparent got %v, %v, expected %v, %v",
+				i, ok, sc.sampled, c.expectedOK, c.expectedSampled)
+		}
+	}
+
+	sc, _ := parseTraceparent(testTraceparent)
+	if sc.traceparent() != testTraceparent {
+		t.Errorf("TestParseTraceparent got %s, expected %s", sc.traceparent(), testTraceparent)
+	}
+}
+
+// exportTestSpans пропускает запрос к /games/{game_slug}/leaderboard с handler
+// через трейсинг и возвращает отправленные в коллектор спаны
+func exportTestSpans(t *testing.T, handler http.HandlerFunc) []otlpSpan {
+	var payload otlpTraces
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("exportTestSpans got invalid payload: %s", err)
+		}
+	}))
+	defer collector.Close()
+
+	srv := initTests()
+	srv.tracer = newTracerWithExporter(newOTLPExporter(collector.URL, "warscript-games"), srv.logger)
+
+	r := mux.NewRouter()
+	r.Use(srv.httpTracingMiddleware)
+	r.HandleFunc("/games/{game_slug}/leaderboard", handler)
+	req := httptest.NewRequest("GET", "/games/pong/leaderboard", nil)
+	req.Header.Set(traceparentHeader, testTraceparent)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	stop := make(chan struct{})
+	close(stop)
+	srv.tracer.run(stop)
+
+	if len(payload.ResourceSpans) != 1 || len(payload.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("exportTestSpans got payload %+v", payload)
+	}
+
+	return payload.ResourceSpans[0].ScopeSpans[0].Spans
+}
+
+func TestTracingExport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs("pong", 0, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "score"}).
+			AddRow(1, 200))
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	spans := exportTestSpans(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, err := gs.GetGameLeaderboardBySlug(r.Context(), "pong", 6, 0); err != nil {
+			t.Errorf("TestTracingExport got unexpected error: %s", err)
+		}
+	})
+	if len(spans) != 3 {
+		t.Fatalf("TestTracingExport got %d spans, expected 3", len(spans))
+	}
+
+	// спаны уходят в порядке окончания: запрос в базу закончился
+	// до похода в warscript-users
+	query, users, server := spans[0], spans[1], spans[2]
+	if server.Name != "GET /games/{game_slug}/leaderboard" || query.Name != "db.GetGameLeaderboardBySlug" ||
+		users.Name != "warscript-users/GetUsersByIDs" {
+		t.Errorf("TestTracingExport got spans %s, %s, %s", server.Name, query.Name, users.Name)
+	}
+	if server.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || query.TraceID != server.TraceID ||
+		users.TraceID != server.TraceID {
+		t.Errorf("TestTracingExport got trace ids %s, %s, %s", server.TraceID, query.TraceID, users.TraceID)
+	}
+	if server.ParentSpanID != "00f067aa0ba902b7" || query.ParentSpanID != server.SpanID ||
+		users.ParentSpanID != server.SpanID {
+		t.Errorf("TestTracingExport got parents %s, %s, %s", server.ParentSpanID, query.ParentSpanID, users.ParentSpanID)
+	}
+
+	queryEnd, _ := strconv.ParseInt(query.EndTimeUnixNano, 10, 64)
+	usersStart, _ := strconv.ParseInt(users.StartTimeUnixNano, 10, 64)
+	if queryEnd > usersStart {
+		t.Errorf("TestTracingExport got query span ending at %d after users span start %d", queryEnd, usersStart)
+	}
+	if query.Status.Code != 0 {
+		t.Errorf("TestTracingExport got query span status %+v", query.Status)
+	}
+}
+
+func TestTracingQueryError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT").WithArgs("pong", 0, 6).
+		WillReturnError(sql.ErrConnDone)
+	gs := NewAccessObject(db, &fakeAuthClient{}, 0)
+
+	spans := exportTestSpans(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, err := gs.GetGameLeaderboardBySlug(r.Context(), "pong", 6, 0); err == nil {
+			t.Errorf("TestTracingQueryError got no error")
+		}
+	})
+	if len(spans) != 2 {
+		t.Fatalf("TestTracingQueryError got %d spans, expected 2", len(spans))
+	}
+
+	if query := spans[0]; query.Name != "db.GetGameLeaderboardBySlug" ||
+		query.Status.Code != 2 || query.Status.Message != sql.ErrConnDone.Error() {
+		t.Errorf("TestTracingQueryError got query span %s with status %+v", query.Name, query.Status)
+	}
+}
+
+func TestTracingGRPCPropagation(t *testing.T) {
+	tr := newTracerWithExporter(nil, newTestLogger())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(traceparentHeader, testTraceparent))
+
+	var outgoing string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		ctx, sp := startSpan(ctx, "warscript-users/GetUsersByIDs", spanClient)
+		defer sp.End()
+
+		md, _ := metadata.FromOutgoingContext(outgoingTraceparent(ctx))
+		if values := md.Get(traceparentHeader); len(values) == 1 {
+			outgoing = values[0]
+		}
+		return nil, nil
+	}
+
+	_, err := unaryTracing(tr)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/models.Games/GetGameBySlug"}, handler)
+	if err != nil {
+		t.Fatalf("TestTracingGRPCPropagation got unexpected error: %s", err)
+	}
+
+	sc, ok := parseTraceparent(outgoing)
+	if !ok || hex.EncodeToString(sc.traceID[:]) != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		strings.Contains(outgoing, "00f067aa0ba902b7") {
+		t.Errorf("TestTracingGRPCPropagation got outgoing traceparent %q", outgoing)
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	var tr *tracer
+	ctx, sp := tr.startRootSpan(context.Background(), "GET /games", spanServer, testTraceparent)
+	if sp != nil || spanFromContext(ctx) != nil {
+		t.Errorf("TestTracingDisabled got span with disabled tracer")
+	}
+
+	// на nil спане всё работает молча
+	sp.SetAttr("http.status_code", 200)
+	sp.End()
+	observeQuery(withQueryMethod(ctx, "GetGameList"), "SELECT 1").finish(nil)
+}
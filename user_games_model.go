@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UserGameModel игра пользователя вместе с его местом в ней
+type UserGameModel struct {
+	Slug           string
+	Title          string
+	BackgroundUUID sql.NullString
+	Score          int32
+	Rank           int64
+	Percentile     float64
+	LastPlayed     time.Time
+}
+
+// GetBackgroundUUID возвращает BackgroundUUID или пустую строку, если его нет в базе
+func (u *UserGameModel) GetBackgroundUUID() string {
+	if u.BackgroundUUID.Valid {
+		return u.BackgroundUUID.String
+	}
+
+	return ""
+}
+
+// GetUserGames все игры, в которых у пользователя есть очки, с его местом,
+// перцентилем (какую долю игроков он обошёл или догнал) и временем последней игры
+func (gs *AccessObject) GetUserGames(ctx context.Context, userID int64) ([]*UserGameModel, error) {
+	ctx = withQueryMethod(ctx, "GetUserGames")
+
+	rows, err := gs.db.QueryContext(ctx, `SELECT g.slug, g.title, g.background_uuid,
+					s.score, s.place, s.percentile, s.last_played
+					FROM (
+						SELECT ug.user_id, ug.game_id, ug.score, ug.last_played,
+							rank() OVER (PARTITION BY ug.game_id ORDER BY ug.score DESC) AS place,
+							100 * cume_dist() OVER (PARTITION BY ug.game_id ORDER BY ug.score) AS percentile
+						FROM visible_users_games ug
+						WHERE ug.game_id IN (SELECT game_id FROM visible_users_games WHERE user_id = $1)
+					) s JOIN games g ON g.id = s.game_id
+					WHERE s.user_id = $1 ORDER BY s.last_played DESC, g.id;`, userID)
+	if err != nil {
+		return nil, internalError(ctx, "get user games error: %v", err)
+	}
+	defer rows.Close()
+
+	userGames := make([]*UserGameModel, 0)
+	for rows.Next() {
+		ug := &UserGameModel{}
+		err = rows.Scan(&ug.Slug, &ug.Title, &ug.BackgroundUUID,
+			&ug.Score, &ug.Rank, &ug.Percentile, &ug.LastPlayed)
+		if err != nil {
+			return nil, internalError(ctx, "get user games scan error: %v", err)
+		}
+		userGames = append(userGames, ug)
+	}
+
+	return userGames, nil
+}
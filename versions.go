@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+	"github.com/HotCodeGroup/warscript-utils/utils"
+	"github.com/gorilla/mux"
+)
+
+// apiVersion версия http api. Хэндлеры у версий общие, отличаются только
+// формы ответов: их приводит к нужной версии adaptResponse
+type apiVersion int
+
+const (
+	apiV1 apiVersion = 1
+	apiV2 apiVersion = 2
+)
+
+// apiVersions все версии, которые обслуживаем, по возрастанию
+var apiVersions = []apiVersion{apiV1, apiV2}
+
+// latestAPIVersion версия, на которую отправляем со старых
+const latestAPIVersion = apiV2
+
+// prefix префикс роутов версии: /v1, /v2
+func (v apiVersion) prefix() string {
+	return "/v" + strconv.Itoa(int(v))
+}
+
+func (v apiVersion) deprecated() bool {
+	return v < latestAPIVersion
+}
+
+type apiVersionKey struct{}
+
+// versionFromContext версия, с которой пришёл запрос; вне роутера -- v1
+func versionFromContext(ctx context.Context) apiVersion {
+	if v, ok := ctx.Value(apiVersionKey{}).(apiVersion); ok {
+		return v
+	}
+
+	return apiV1
+}
+
+// versionMiddleware кладёт версию в контекст, а ответы устаревших версий
+// помечает заголовками Deprecation и Link на тот же роут в новой версии
+func versionMiddleware(v apiVersion) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if v.deprecated() {
+				successor := latestAPIVersion.prefix() + strings.TrimPrefix(r.URL.Path, v.prefix())
+				w.Header().Set("Deprecation", "true")
+				w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, v)))
+		})
+	}
+}
+
+// writeJSON успешный ответ в форме версии, с которой пришёл запрос
+func writeJSON(ctx context.Context, w http.ResponseWriter, code int, resp interface{}) {
+	utils.WriteApplicationJSON(w, code, adaptResponse(versionFromContext(ctx), resp))
+}
+
+// adaptResponse ответ хэндлера (всегда в форме v1) в форме версии v.
+// Ответы, которые в версиях не отличаются, отдаются как есть
+func adaptResponse(v apiVersion, resp interface{}) interface{} {
+	if v == apiV2 {
+		return v2Response(resp)
+	}
+
+	return resp
+}
+
+func v2User(u *jmodels.InfoUser) *jmodels.User {
+	return &jmodels.User{
+		ID:        u.ID,
+		Username:  u.Username,
+		PhotoUUID: u.PhotoUUID,
+		Active:    u.Active,
+	}
+}
+
+// v2Response в v2 игрок во всех leaderboard вложен в поле user
+func v2Response(resp interface{}) interface{} {
+	switch leaders := resp.(type) {
+	case []*jmodels.ScoredUser:
+		adapted := make([]*jmodels.ScoredUserV2, len(leaders))
+		for i, l := range leaders {
+			adapted[i] = &jmodels.ScoredUserV2{User: v2User(&l.InfoUser), Score: l.Score}
+		}
+		return adapted
+	case []*jmodels.RankedUser:
+		adapted := make([]*jmodels.RankedUserV2, len(leaders))
+		for i, l := range leaders {
+			adapted[i] = &jmodels.RankedUserV2{
+				User:       v2User(&l.InfoUser),
+				Score:      l.Score,
+				Rank:       l.Rank,
+				GlobalRank: l.GlobalRank,
+			}
+		}
+		return adapted
+	case []*jmodels.WindowLeader:
+		adapted := make([]*jmodels.WindowLeaderV2, len(leaders))
+		for i, l := range leaders {
+			adapted[i] = &jmodels.WindowLeaderV2{User: v2User(&l.InfoUser), Points: l.Points, Rank: l.Rank}
+		}
+		return adapted
+	case []*jmodels.GlobalLeader:
+		adapted := make([]*jmodels.GlobalLeaderV2, len(leaders))
+		for i, l := range leaders {
+			adapted[i] = &jmodels.GlobalLeaderV2{
+				User:        v2User(&l.InfoUser),
+				Rating:      l.Rating,
+				Rank:        l.Rank,
+				GamesPlayed: l.GamesPlayed,
+			}
+		}
+		return adapted
+	}
+
+	return resp
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HotCodeGroup/warscript-games/jmodels"
+)
+
+func TestVersionHeaders(t *testing.T) {
+	ts := httptest.NewServer(initTests().httpHandler(newHealthChecker(&pingerTest{}, nil)))
+	defer ts.Close()
+
+	cases := []struct {
+		path               string
+		expectedCode       int
+		expectedDeprecated string
+		expectedLink       string
+	}{
+		{path: "/v1/games/pong", expectedCode: http.StatusOK,
+			expectedDeprecated: "true", expectedLink: `</v2/games/pong>; rel="successor-version"`},
+		{path: "/v1/games/ping", expectedCode: http.StatusNotFound,
+			expectedDeprecated: "true", expectedLink: `</v2/games/ping>; rel="successor-version"`},
+		{path: "/v2/games/pong", expectedCode: http.StatusOK},
+		{path: "/v3/games/pong", expectedCode: http.StatusNotFound},
+	}
+
+	for i, c := range cases {
+		resp, err := http.Get(ts.URL + c.path)
+		if err != nil {
+			t.Fatalf("[%d] TestVersionHeaders got unexpected error: %v", i, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != c.expectedCode {
+			t.Errorf("[%d] TestVersionHeaders got code %d, expected %d", i, resp.StatusCode, c.expectedCode)
+		}
+		if got := resp.Header.Get("Deprecation"); got != c.expectedDeprecated {
+			t.Errorf("[%d] TestVersionHeaders got Deprecation %q, expected %q", i, got, c.expectedDeprecated)
+		}
+		if got := resp.Header.Get("Link"); got != c.expectedLink {
+			t.Errorf("[%d] TestVersionHeaders got Link %q, expected %q", i, got, c.expectedLink)
+		}
+	}
+}
+
+func TestAdaptResponse(t *testing.T) {
+	user := jmodels.InfoUser{
+		BasicUser: jmodels.BasicUser{Username: "sleep", PhotoUUID: "uuid"},
+		ID:        1,
+		Active:    true,
+	}
+	game := &jmodels.Game{Slug: "pong", Title: "Pong"}
+
+	cases := []struct {
+		version      apiVersion
+		resp         interface{}
+		expectedBody string
+	}{
+		{version: apiV1, resp: []*jmodels.ScoredUser{{InfoUser: user, Score: 10}},
+			expectedBody: `[{"score":10,"id":1,"active":true,"username":"sleep","photo_uuid":"uuid"}]`},
+		{version: apiV2, resp: []*jmodels.ScoredUser{{InfoUser: user, Score: 10}},
+			expectedBody: `[{"user":{"id":1,"username":"sleep","photo_uuid":"uuid","active":true},"score":10}]`},
+		{version: apiV2, resp: []*jmodels.RankedUser{{ScoredUser: jmodels.ScoredUser{InfoUser: user, Score: 10}, Rank: 1, GlobalRank: 3}},
+			expectedBody: `[{"user":{"id":1,"username":"sleep","photo_uuid":"uuid","active":true},"score":10,"rank":1,"global_rank":3}]`},
+		{version: apiV2, resp: []*jmodels.WindowLeader{{InfoUser: user, Points: 7, Rank: 2}},
+			expectedBody: `[{"user":{"id":1,"username":"sleep","photo_uuid":"uuid","active":true},"points":7,"rank":2}]`},
+		{version: apiV2, resp: []*jmodels.GlobalLeader{{InfoUser: user, Rating: 1.5, Rank: 1, GamesPlayed: 2}},
+			expectedBody: `[{"user":{"id":1,"username":"sleep","photo_uuid":"uuid","active":true},"rating":1.5,"rank":1,"games_played":2}]`},
+		{version: apiV2, resp: game,
+			expectedBody: `{"slug":"pong","title":"Pong","background_uuid":""}`},
+	}
+
+	for i, c := range cases {
+		body, err := json.Marshal(adaptResponse(c.version, c.resp))
+		if err != nil {
+			t.Fatalf("[%d] TestAdaptResponse got unexpected error: %v", i, err)
+		}
+		if string(body) != c.expectedBody {
+			t.Errorf("[%d] TestAdaptResponse got %s, expected %s", i, body, c.expectedBody)
+		}
+	}
+}
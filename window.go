@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// WindowDay leaderboard за сутки
+	WindowDay = "day"
+	// WindowWeek leaderboard за неделю (с понедельника)
+	WindowWeek = "week"
+	// WindowMonth leaderboard за календарный месяц
+	WindowMonth = "month"
+)
+
+// LeaderboardWindow полуинтервал [Start, End) для leaderboard по времени
+type LeaderboardWindow struct {
+	Period string
+	Start  time.Time
+	End    time.Time
+}
+
+// Closed окно закончилось, и очки в нём больше не поменяются
+func (w *LeaderboardWindow) Closed(now time.Time) bool {
+	return !now.Before(w.End)
+}
+
+// Previous окно того же периода, предшествующее текущему
+func (w *LeaderboardWindow) Previous() *LeaderboardWindow {
+	prev, _ := NewLeaderboardWindow(w.Period, w.Start.Add(-time.Nanosecond), w.Start.Location())
+	return prev
+}
+
+// IsLeaderboardPeriod проверяет, что такой период существует
+func IsLeaderboardPeriod(period string) bool {
+	return period == WindowDay || period == WindowWeek || period == WindowMonth
+}
+
+// NewLeaderboardWindow окно периода period, в которое попадает момент at;
+// границы считаются по календарю часового пояса loc
+func NewLeaderboardWindow(period string, at time.Time, loc *time.Location) (*LeaderboardWindow, bool) {
+	at = at.In(loc)
+	dayStart := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, loc)
+
+	w := &LeaderboardWindow{
+		Period: period,
+	}
+	switch period {
+	case WindowDay:
+		w.Start = dayStart
+		w.End = dayStart.AddDate(0, 0, 1)
+	case WindowWeek:
+		// неделя начинается с понедельника
+		daysFromMonday := (int(at.Weekday()) + 6) % 7
+		w.Start = dayStart.AddDate(0, 0, -daysFromMonday)
+		w.End = w.Start.AddDate(0, 0, 7)
+	case WindowMonth:
+		w.Start = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, loc)
+		w.End = w.Start.AddDate(0, 1, 0)
+	default:
+		return nil, false
+	}
+
+	return w, true
+}
+
+// snapshotPreviousWindows сохраняет только что закрывшиеся окна всех периодов
+func (s *Server) snapshotPreviousWindows(ctx context.Context, now time.Time) {
+	for _, period := range []string{WindowDay, WindowWeek, WindowMonth} {
+		w, _ := NewLeaderboardWindow(period, now, s.location)
+		if err := s.games.SnapshotWindow(ctx, w.Previous()); err != nil {
+			s.logger.Errorf("can not snapshot previous %s leaderboard window: %s", period, err)
+		}
+	}
+}
+
+// runWindowSnapshots периодически сохраняет закрывшиеся окна, чтобы запросы
+// за прошлый день/неделю/месяц не считали их каждый раз. Останавливается
+// по закрытию stop, чтобы не ходить в уже закрытую базу; снапшот,
+// который идёт в этот момент, отменяется
+func (s *Server) runWindowSnapshots(interval time.Duration, stop <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.snapshotPreviousWindows(ctx, time.Now())
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			s.snapshotPreviousWindows(ctx, now)
+		case <-stop:
+			return
+		}
+	}
+}
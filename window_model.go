@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/HotCodeGroup/warscript-utils/utils"
+
+	"github.com/pkg/errors"
+)
+
+// WindowScoredUserModel пользователь с очками, набранными за окно
+type WindowScoredUserModel struct {
+	ID        int64
+	Username  string
+	PhotoUUID sql.NullString
+	Active    bool
+	Points    int64
+	Rank      int64
+}
+
+// GetPhotoUUID возвращает photoUUID или пустую строку, если его нет в базе
+func (u *WindowScoredUserModel) GetPhotoUUID() string {
+	if u.PhotoUUID.Valid {
+		return u.PhotoUUID.String
+	}
+
+	return ""
+}
+
+// GetGameWindowLeaderboardBySlug leaderboard по очкам, набранным за окно w.
+// Сохранённое закрытое окно отдаётся из leaderboard_snapshots, остальные
+// считаются на лету по score_events. Сам запрос ничего не сохраняет:
+// снапшоты пишут только runWindowSnapshots и recompute. Скрытых сейчас
+// игроков отбрасываем при чтении и места пересчитываем без них
+func (gs *AccessObject) GetGameWindowLeaderboardBySlug(ctx context.Context, slug string, w *LeaderboardWindow,
+	limit, offset int) ([]*WindowScoredUserModel, error) {
+	ctx = withQueryMethod(ctx, "GetGameWindowLeaderboardBySlug")
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, internalError(ctx, "can not open GetGameWindowLeaderboardBySlug transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	g, err := gs.getGameImpl(ctx, tx, "slug", slug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, utils.ErrNotExists
+		}
+
+		return nil, internalError(ctx, "GetGameWindowLeaderboardBySlug can not get game by slug: %v", err)
+	}
+
+	snapshotted := false
+	if w.Closed(time.Now()) {
+		err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM leaderboard_snapshot_windows
+					WHERE game_id = $1 AND period = $2 AND window_start = $3);`,
+			g.ID, w.Period, w.Start).Scan(&snapshotted)
+		if err != nil {
+			return nil, internalError(ctx, "can not check window snapshot: %v", err)
+		}
+	}
+
+	var rows *observedRows
+	if snapshotted {
+		rows, err = tx.QueryContext(ctx, `SELECT user_id, points, rank() OVER (ORDER BY points DESC) AS place
+					FROM leaderboard_snapshots
+					WHERE game_id = $1 AND period = $2 AND window_start = $3
+						AND user_id NOT IN (SELECT user_id FROM users_games WHERE game_id = $1 AND hidden)
+					ORDER BY points DESC, user_id OFFSET $4 LIMIT $5;`, g.ID, w.Period, w.Start, offset, limit)
+	} else {
+		rows, err = tx.QueryContext(ctx, `SELECT user_id, sum(new_score - old_score) AS points,
+					rank() OVER (ORDER BY sum(new_score - old_score) DESC) AS place
+					FROM score_events
+					WHERE game_id = $1 AND created_at >= $2 AND created_at < $3
+						AND user_id NOT IN (SELECT user_id FROM users_games WHERE game_id = $1 AND hidden)
+					GROUP BY user_id ORDER BY points DESC, user_id OFFSET $4 LIMIT $5;`,
+			g.ID, w.Start, w.End, offset, limit)
+	}
+	if err != nil {
+		return nil, internalError(ctx, "get window leaderboard error: %v", err)
+	}
+	defer rows.Close()
+
+	IDs := make([]int64, 0)
+	leaderboard := make([]*WindowScoredUserModel, 0)
+	for rows.Next() {
+		leader := &WindowScoredUserModel{}
+		if err = rows.Scan(&leader.ID, &leader.Points, &leader.Rank); err != nil {
+			return nil, internalError(ctx, "get window leaderboard scan user error: %v", err)
+		}
+		leaderboard = append(leaderboard, leader)
+		IDs = append(IDs, leader.ID)
+	}
+
+	if len(leaderboard) == 0 {
+		return nil, utils.ErrNotExists
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, internalError(ctx, "can not commit GetGameWindowLeaderboardBySlug transaction: %v", err)
+	}
+
+	users, err := gs.getUsersInfo(ctx, IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leader := range leaderboard {
+		if user, ok := users[leader.ID]; ok {
+			leader.Username = user.Username
+			leader.Active = user.Active
+			leader.PhotoUUID = photoUUIDToNull(user.PhotoUUID)
+		}
+	}
+
+	return leaderboard, nil
+}
+
+// SnapshotWindow сохраняет leaderboard закрытого окна w для всех игр
+func (gs *AccessObject) SnapshotWindow(ctx context.Context, w *LeaderboardWindow) error {
+	ctx = withQueryMethod(ctx, "SnapshotWindow")
+
+	if !w.Closed(time.Now()) {
+		return errors.Wrap(utils.ErrInvalid, "can not snapshot open window")
+	}
+
+	tx, err := gs.db.BeginTx(ctx, nil)
+	if err != nil {
+		return internalError(ctx, "can not open SnapshotWindow transaction: %v", err)
+	}
+
+	//nolint: errcheck
+	defer tx.Rollback()
+
+	if err = gs.snapshotGamesImpl(ctx, tx, w); err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return internalError(ctx, "can not commit SnapshotWindow transaction: %v", err)
+	}
+
+	return nil
+}
+
+// snapshotGamesImpl сохраняет окно w для каждой игры
+func (gs *AccessObject) snapshotGamesImpl(ctx context.Context, tx *observedTx, w *LeaderboardWindow) error {
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM games ORDER BY id;`)
+	if err != nil {
+		return internalError(ctx, "snapshot can not get games: %v", err)
+	}
+
+	gameIDs := make([]int64, 0)
+	for rows.Next() {
+		var gameID int64
+		if err = rows.Scan(&gameID); err != nil {
+			rows.Close()
+			return internalError(ctx, "snapshot games scan error: %v", err)
+		}
+		gameIDs = append(gameIDs, gameID)
+	}
+	rows.Close()
+
+	for _, gameID := range gameIDs {
+		if err = gs.snapshotWindowImpl(ctx, tx, gameID, w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// snapshotWindowImpl один раз переносит результаты закрытого окна
+// в leaderboard_snapshots. Уникальность leaderboard_snapshot_windows
+// не даёт двум инстансам посчитать одно окно дважды. Сохраняем всех игроков,
+// в том числе скрытых: скрытие можно снять, а снапшот уже не пересчитается
+func (gs *AccessObject) snapshotWindowImpl(ctx context.Context, tx *observedTx, gameID int64, w *LeaderboardWindow) error {
+	res, err := tx.ExecContext(ctx, `INSERT INTO leaderboard_snapshot_windows (game_id, period, window_start)
+					VALUES ($1, $2, $3) ON CONFLICT DO NOTHING;`, gameID, w.Period, w.Start)
+	if err != nil {
+		return internalError(ctx, "can not mark window snapshot: %v", err)
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return internalError(ctx, "window snapshot rows affected error: %v", err)
+	}
+
+	// уже посчитано раньше
+	if created == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO leaderboard_snapshots (game_id, period, window_start, user_id, points, place)
+					SELECT $1, $2, $3, user_id, sum(new_score - old_score),
+						rank() OVER (ORDER BY sum(new_score - old_score) DESC)
+					FROM score_events
+					WHERE game_id = $1 AND created_at >= $3 AND created_at < $4
+					GROUP BY user_id;`, gameID, w.Period, w.Start, w.End)
+	if err != nil {
+		return internalError(ctx, "can not save window snapshot: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLeaderboardWindow(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	// 2019-05-22 среда, 23:30 UTC -- уже четверг по Москве
+	at := time.Date(2019, 5, 22, 23, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		period string
+		loc    *time.Location
+		start  time.Time
+		end    time.Time
+	}{
+		{
+			period: WindowDay,
+			loc:    time.UTC,
+			start:  time.Date(2019, 5, 22, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2019, 5, 23, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			period: WindowDay,
+			loc:    moscow,
+			start:  time.Date(2019, 5, 23, 0, 0, 0, 0, moscow),
+			end:    time.Date(2019, 5, 24, 0, 0, 0, 0, moscow),
+		},
+		{
+			period: WindowWeek,
+			loc:    time.UTC,
+			start:  time.Date(2019, 5, 20, 0, 0, 0, 0, time.UTC),
+			end:    time.Date(2019, 5, 27, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			period: WindowMonth,
+			loc:    moscow,
+			start:  time.Date(2019, 5, 1, 0, 0, 0, 0, moscow),
+			end:    time.Date(2019, 6, 1, 0, 0, 0, 0, moscow),
+		},
+	}
+
+	for i, c := range cases {
+		w, ok := NewLeaderboardWindow(c.period, at, c.loc)
+		if !ok {
+			t.Fatalf("[%d] NewLeaderboardWindow unexpectedly failed for %s", i, c.period)
+		}
+		if !w.Start.Equal(c.start) || !w.End.Equal(c.end) {
+			t.Errorf("[%d] NewLeaderboardWindow returns [%v, %v), wanted: [%v, %v)",
+				i, w.Start, w.End, c.start, c.end)
+		}
+	}
+
+	if _, ok := NewLeaderboardWindow("year", at, time.UTC); ok {
+		t.Errorf("NewLeaderboardWindow accepted unknown period")
+	}
+
+	w, _ := NewLeaderboardWindow(WindowMonth, at, time.UTC)
+	prev := w.Previous()
+	if !prev.Start.Equal(time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)) || !prev.End.Equal(w.Start) {
+		t.Errorf("Previous returns [%v, %v)", prev.Start, prev.End)
+	}
+
+	if w.Closed(at) || !prev.Closed(at) {
+		t.Errorf("Closed returns wrong result")
+	}
+}